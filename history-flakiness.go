@@ -0,0 +1,107 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// FlakinessScore rates how flaky a test case was over many builds
+type FlakinessScore struct {
+	// Full qualified name of the test case
+	Fullname string
+
+	// The amount of builds the test case ran in, not counting those it was skipped in
+	Runs int
+
+	// The amount of times the test case passed after it failed or failed after it passed, from one build to the next
+	Flips int
+
+	// Flips relative to the changes possible between the runs
+	FlipRate float64
+
+	// The amount of builds in which the test case only passed on a rerun
+	FlakyRuns int
+
+	// The amount of failed executions which were rerun in the builds the test case passed in at last, at least
+	// one per flaky run
+	Reruns int
+
+	// Reruns relative to all executions, so a test case needing several reruns to pass is flakier than one
+	// needing a single rerun
+	FlakyRate float64
+
+	// How flaky the test case is between 0 and 1, combining flip rate and flaky rate
+	Score float64
+
+	// How much the score can be trusted between 0 and 1, growing with the amount of runs
+	Confidence float64
+}
+
+// Rank weighs the score by its confidence
+func (s FlakinessScore) Rank() float64 {
+	return s.Score * s.Confidence
+}
+
+// ScoreFlakiness scores the test cases of given history records, e.g. queried from a HistoryStore. Only test cases
+// with a score above 0 are returned, the flakiest first as ranked by Rank
+func ScoreFlakiness(records []HistoryRecord) []FlakinessScore {
+	records = slices.Clone(records)
+	slices.SortStableFunc(records, func(a, b HistoryRecord) int {
+		return a.Time.Compare(b.Time)
+	})
+
+	scores := make(map[string]*FlakinessScore)
+	last := make(map[string]bool)
+	for _, record := range records {
+		if record.Status == Skip {
+			continue
+		}
+		score, ok := scores[record.Fullname]
+		if !ok {
+			score = &FlakinessScore{Fullname: record.Fullname}
+			scores[record.Fullname] = score
+		}
+
+		passed := record.Status == Success || record.Status == Flaky
+		if score.Runs > 0 && passed != last[record.Fullname] {
+			score.Flips++
+		}
+		last[record.Fullname] = passed
+		if record.Status == Flaky || passed && record.Reruns > 0 {
+			score.FlakyRuns++
+			score.Reruns += max(record.Reruns, 1)
+		}
+		score.Runs++
+	}
+
+	ranked := make([]FlakinessScore, 0)
+	for _, score := range scores {
+		if score.Runs > 1 {
+			score.FlipRate = float64(score.Flips) / float64(score.Runs-1)
+		}
+		score.FlakyRate = float64(score.Reruns) / float64(score.Runs+score.Reruns)
+		score.Score = 1 - (1-score.FlipRate)*(1-score.FlakyRate)
+		score.Confidence = 1 - 1/math.Sqrt(float64(score.Runs))
+		if score.Score > 0 {
+			ranked = append(ranked, *score)
+		}
+	}
+	slices.SortFunc(ranked, func(a, b FlakinessScore) int {
+		return cmp.Or(cmp.Compare(b.Rank(), a.Rank()), cmp.Compare(b.Score, a.Score), cmp.Compare(a.Fullname, b.Fullname))
+	})
+
+	return ranked
+}
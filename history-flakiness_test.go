@@ -0,0 +1,115 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"testing"
+	"time"
+
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestScoreFlakiness(t *testing.T) {
+	var records []HistoryRecord
+	outcomes := map[string][]Status{
+		"A.Flipping": {Success, Failure, Skip, Success, Error},
+		"A.Rerun":    {Success, Flaky, Success, Success},
+		"A.Broken":   {Failure, Failure, Failure, Failure},
+		"A.Stable":   {Success, Success, Success, Success},
+		"A.New":      {Flaky},
+	}
+	// recorded in reverse order, scoring orders by time
+	for fullname, statuses := range outcomes {
+		for i, status := range statuses {
+			records = append([]HistoryRecord{{
+				Fullname: fullname,
+				Status:   status,
+				Time:     time.Date(2024, 3, 1+i, 12, 0, 0, 0, time.UTC),
+			}}, records...)
+		}
+	}
+
+	assert := a.New(t)
+	scores := ScoreFlakiness(records)
+
+	assert.Equal(3, len(scores))
+	assert.Equal(FlakinessScore{
+		Fullname:   "A.Flipping",
+		Runs:       4,
+		Flips:      3,
+		FlipRate:   1,
+		Score:      1,
+		Confidence: 0.5,
+	}, scores[0])
+
+	// one rerun out of five executions
+	assert.Equal("A.Rerun", scores[1].Fullname)
+	assert.Equal(0, scores[1].Flips)
+	assert.Equal(1, scores[1].FlakyRuns)
+	assert.Equal(1, scores[1].Reruns)
+	assert.Equal(0.2, scores[1].FlakyRate)
+	assert.InDelta(0.2, scores[1].Score, 0.0001)
+	assert.InDelta(0.1, scores[1].Rank(), 0.0001)
+
+	// a single run scores high, but without any confidence
+	assert.Equal("A.New", scores[2].Fullname)
+	assert.Equal(0.5, scores[2].Score)
+	assert.Equal(0.0, scores[2].Confidence)
+}
+
+func TestScoreFlakinessOfRecordedBuilds(t *testing.T) {
+	assert := a.New(t)
+	var records []HistoryRecord
+	for i, failing := range []bool{false, true, false} {
+		build := BuildInfo{BuildID: string(rune('1' + i)), Time: time.Date(2024, 3, 1+i, 12, 0, 0, 0, time.UTC)}
+		records = append(records, HistoryRecords(build, buildResults(failing))...)
+	}
+
+	scores := ScoreFlakiness(records)
+	assert.Equal(1, len(scores))
+	assert.Equal("A.Test-2", scores[0].Fullname)
+	assert.Equal(2, scores[0].Flips)
+}
+
+func TestScoreFlakinessByReruns(t *testing.T) {
+	var records []HistoryRecord
+	reruns := map[string][]HistoryRecord{
+		"A.Once":   {{Status: Flaky, Reruns: 1}, {Status: Success}, {Status: Success}},
+		"A.Thrice": {{Status: Flaky, Reruns: 3}, {Status: Success}, {Status: Success}},
+		// passed on a rerun, as recorded by tools without flaky status
+		"A.Success": {{Status: Success}, {Status: Success, Reruns: 1}, {Status: Success}},
+		// failing in every execution is broken, not flaky
+		"A.Broken": {{Status: Failure, Reruns: 2}, {Status: Failure, Reruns: 2}, {Status: Failure, Reruns: 2}},
+	}
+	for fullname, runs := range reruns {
+		for i, record := range runs {
+			record.Fullname = fullname
+			record.Time = time.Date(2024, 3, 1+i, 12, 0, 0, 0, time.UTC)
+			records = append(records, record)
+		}
+	}
+
+	assert := a.New(t)
+	scores := ScoreFlakiness(records)
+
+	assert.Equal(3, len(scores))
+	assert.Equal("A.Thrice", scores[0].Fullname)
+	assert.Equal(1, scores[0].FlakyRuns)
+	assert.Equal(3, scores[0].Reruns)
+	assert.Equal(0.5, scores[0].FlakyRate)
+
+	assert.Equal("A.Once", scores[1].Fullname)
+	assert.Equal(0.25, scores[1].FlakyRate)
+	assert.Equal("A.Success", scores[2].Fullname)
+	assert.Equal(scores[1].FlakyRate, scores[2].FlakyRate)
+}
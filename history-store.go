@@ -0,0 +1,291 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrNoHistory is returned by queries which need records when there are none
+var ErrNoHistory = errors.New("no history recorded")
+
+// BuildInfo identifies the build test results were recorded for
+type BuildInfo struct {
+	// Commit the build ran for
+	Commit string
+
+	// Branch the build ran for
+	Branch string
+
+	// Id of the build in the CI system
+	BuildID string
+
+	// When the build ran, the time of recording if zero
+	Time time.Time
+}
+
+// HistoryRecord is the outcome of a test case in a build
+type HistoryRecord struct {
+	Fullname string        `json:"fullname"`
+	Suite    string        `json:"suite"`
+	Commit   string        `json:"commit"`
+	Branch   string        `json:"branch"`
+	BuildID  string        `json:"buildId"`
+	Time     time.Time     `json:"time"`
+	Status   Status        `json:"status"`
+	Duration time.Duration `json:"duration"`
+
+	// Message of the issue the test case had, if any
+	Message string `json:"message,omitempty"`
+
+	// The amount of reruns of the test case within the build
+	Reruns int `json:"reruns,omitempty"`
+}
+
+// HistoryQuery selects history records. Empty fields match all records
+type HistoryQuery struct {
+	// Full qualified name of the test case
+	Fullname string
+
+	// Branch the builds ran for
+	Branch string
+
+	// Commit the builds ran for
+	Commit string
+
+	// Id of the build in the CI system
+	BuildID string
+
+	// Earliest time of the builds, inclusive
+	Since time.Time
+
+	// Latest time of the builds, exclusive
+	Until time.Time
+}
+
+// Matches tells whether record is selected by the query
+func (q HistoryQuery) Matches(record HistoryRecord) bool {
+	return (q.Fullname == "" || q.Fullname == record.Fullname) &&
+		(q.Branch == "" || q.Branch == record.Branch) &&
+		(q.Commit == "" || q.Commit == record.Commit) &&
+		(q.BuildID == "" || q.BuildID == record.BuildID) &&
+		(q.Since.IsZero() || !record.Time.Before(q.Since)) &&
+		(q.Until.IsZero() || record.Time.Before(q.Until))
+}
+
+// HistoryStore keeps the outcomes of test cases across builds
+type HistoryStore interface {
+	// Record adds the outcome of every test case of results in given build
+	Record(build BuildInfo, results TestResults) error
+
+	// Query returns the records selected by query, in the order they were recorded
+	Query(query HistoryQuery) ([]HistoryRecord, error)
+}
+
+// HistoryRecords converts the test cases of results into history records of given build
+func HistoryRecords(build BuildInfo, results TestResults) []HistoryRecord {
+	if build.Time.IsZero() {
+		build.Time = time.Now()
+	}
+
+	records := make([]HistoryRecord, 0, results.Tests())
+	for _, suite := range results.TestSuites() {
+		for _, testCase := range suite.TestCases() {
+			record := HistoryRecord{
+				Fullname: testCase.Fullname,
+				Suite:    suite.Name(),
+				Commit:   build.Commit,
+				Branch:   build.Branch,
+				BuildID:  build.BuildID,
+				Time:     build.Time,
+				Status:   testCase.Status,
+				Duration: testCase.Duration(),
+				Reruns: testCase.AmountRerunFailures + testCase.AmountRerunErrors +
+					testCase.AmountFlakyFailures + testCase.AmountFlakyErrors,
+			}
+			if testCase.Issue != nil {
+				record.Message = testCase.Issue.Message
+			}
+			records = append(records, record)
+		}
+	}
+
+	return records
+}
+
+// FileHistoryStore is a HistoryStore keeping records as JSON lines in a single file. A record left incomplete
+// at the end of the file by an interrupted Record is ignored by Query and overwritten by the next Record
+type FileHistoryStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewFileHistoryStore creates a store keeping its records in the file at path, which is created on the first record
+func NewFileHistoryStore(path string) *FileHistoryStore {
+	return &FileHistoryStore{path: path}
+}
+
+func (s *FileHistoryStore) Record(build BuildInfo, results TestResults) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, file.Close())
+	}()
+
+	// append after the last complete record
+	end, err := completeLength(file)
+	if err != nil {
+		return err
+	}
+	if err := file.Truncate(end); err != nil {
+		return err
+	}
+	if _, err := file.Seek(end, io.SeekStart); err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, record := range HistoryRecords(build, results) {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
+
+func (s *FileHistoryStore) Query(query HistoryQuery) ([]HistoryRecord, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	records := make([]HistoryRecord, 0)
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for number := 1; ; number++ {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// a last line without line break is a record which was not completely written
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading history %s: %w", s.path, err)
+		}
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var record HistoryRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, fmt.Errorf("error reading history %s at line %d: %w", s.path, number, err)
+		}
+		if query.Matches(record) {
+			records = append(records, record)
+		}
+	}
+}
+
+// completeLength returns the length of file up to and including the line break of its last complete line
+func completeLength(file *os.File) (int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	buffer := make([]byte, 4096)
+	for end := info.Size(); end > 0; {
+		start := max(0, end-int64(len(buffer)))
+		n, err := file.ReadAt(buffer[:end-start], start)
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		if i := bytes.LastIndexByte(buffer[:n], '\n'); i >= 0 {
+			return start + int64(i) + 1, nil
+		}
+		end = start
+	}
+
+	return 0, nil
+}
+
+// PassRate is the share of records selected by query which succeeded, including flaky ones. Skipped records are
+// not taken into account. Returns ErrNoHistory if no record was selected
+func PassRate(store HistoryStore, query HistoryQuery) (float64, error) {
+	records, err := store.Query(query)
+	if err != nil {
+		return 0, err
+	}
+
+	var passed, ran int
+	for _, record := range records {
+		switch record.Status {
+		case Skip:
+			continue
+		case Success, Flaky:
+			passed++
+		}
+		ran++
+	}
+	if ran == 0 {
+		return 0, ErrNoHistory
+	}
+
+	return float64(passed) / float64(ran), nil
+}
+
+// LastFailure returns the last failing, erroneous or quarantined record selected by query, nil if there is none
+func LastFailure(store HistoryStore, query HistoryQuery) (*HistoryRecord, error) {
+	records, err := store.Query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(records) - 1; i >= 0; i-- {
+		if status := records[i].Status; status == Failure || status == Error || status == Quarantined {
+			return &records[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// FirstSeen returns the first record selected by query, nil if there is none
+func FirstSeen(store HistoryStore, query HistoryQuery) (*HistoryRecord, error) {
+	records, err := store.Query(query)
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+
+	return &records[0], nil
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	a "github.com/stretchr/testify/assert"
+)
+
+// buildResults are results with a single test case A.Test-1 and the test case A.Test-2 failing if failing is set
+func buildResults(failing bool) TestResults {
+	var failure *surefireProblem
+	if failing {
+		failure = &surefireProblem{Message: "Failure-1"}
+	}
+	return NewJUnitReportsReaderBuilder().Build().FromJUnitRepresentation([]surefireTestsuite{
+		{
+			Name: "Suite-A",
+			Testcases: []surefireTestcase{
+				{Name: "Test-1", Classname: "A", Time: 0.5},
+				{Name: "Test-2", Classname: "A", Failure: failure},
+			},
+		},
+	})
+}
+
+func TestFileHistoryStore(t *testing.T) {
+	assert := a.New(t)
+	store := NewFileHistoryStore(filepath.Join(t.TempDir(), "history.jsonl"))
+	day := func(day int) time.Time { return time.Date(2024, 3, day, 12, 0, 0, 0, time.UTC) }
+
+	records, err := store.Query(HistoryQuery{})
+	assert.Nil(err)
+	assert.Empty(records)
+
+	assert.Nil(store.Record(BuildInfo{Commit: "c1", Branch: "main", BuildID: "1", Time: day(1)}, buildResults(false)))
+	assert.Nil(store.Record(BuildInfo{Commit: "c2", Branch: "main", BuildID: "2", Time: day(2)}, buildResults(true)))
+	assert.Nil(store.Record(BuildInfo{Commit: "c3", Branch: "feature", BuildID: "3", Time: day(3)}, buildResults(true)))
+	assert.Nil(store.Record(BuildInfo{Commit: "c4", Branch: "main", BuildID: "4", Time: day(4)}, buildResults(false)))
+
+	records, err = store.Query(HistoryQuery{Fullname: "A.Test-1"})
+	assert.Nil(err)
+	assert.Equal(4, len(records))
+	assert.Equal(HistoryRecord{
+		Fullname: "A.Test-1",
+		Suite:    "Suite-A",
+		Commit:   "c1",
+		Branch:   "main",
+		BuildID:  "1",
+		Time:     day(1),
+		Status:   Success,
+		Duration: 500 * time.Millisecond,
+	}, records[0])
+
+	rate, err := PassRate(store, HistoryQuery{Fullname: "A.Test-2", Branch: "main"})
+	assert.Nil(err)
+	assert.InDelta(2.0/3.0, rate, 0.0001)
+
+	lastFailure, err := LastFailure(store, HistoryQuery{Fullname: "A.Test-2"})
+	assert.Nil(err)
+	assert.Equal("c3", lastFailure.Commit)
+	assert.Equal("Failure-1", lastFailure.Message)
+
+	lastFailure, err = LastFailure(store, HistoryQuery{Fullname: "A.Test-2", Since: day(4)})
+	assert.Nil(err)
+	assert.Nil(lastFailure)
+
+	firstSeen, err := FirstSeen(store, HistoryQuery{Fullname: "A.Test-2", Since: day(2), Until: day(4)})
+	assert.Nil(err)
+	assert.Equal("2", firstSeen.BuildID)
+
+	_, err = PassRate(store, HistoryQuery{Fullname: "A.Unknown"})
+	assert.ErrorIs(err, ErrNoHistory)
+}
+
+func TestLastQuarantinedFailure(t *testing.T) {
+	assert := a.New(t)
+	store := NewFileHistoryStore(filepath.Join(t.TempDir(), "history.jsonl"))
+	quarantined := NewJUnitReportsReaderBuilder().WithQuarantine(QuarantineEntry{Pattern: "A.Test-2"}).Build().
+		FromJUnitRepresentation([]surefireTestsuite{{Name: "Suite-A", Testcases: []surefireTestcase{
+			{Name: "Test-2", Classname: "A", Failure: &surefireProblem{Message: "Failure-2"}},
+		}}})
+	assert.Nil(store.Record(BuildInfo{BuildID: "1"}, buildResults(true)))
+	assert.Nil(store.Record(BuildInfo{BuildID: "2"}, quarantined))
+	assert.Nil(store.Record(BuildInfo{BuildID: "3"}, buildResults(false)))
+
+	lastFailure, err := LastFailure(store, HistoryQuery{Fullname: "A.Test-2"})
+	assert.Nil(err)
+	assert.Equal("2", lastFailure.BuildID)
+	assert.Equal(Quarantined, lastFailure.Status)
+}
+
+func TestFileHistoryStoreQueryBuild(t *testing.T) {
+	assert := a.New(t)
+	store := NewFileHistoryStore(filepath.Join(t.TempDir(), "history.jsonl"))
+	assert.Nil(store.Record(BuildInfo{Commit: "c1", Branch: "main", BuildID: "1"}, buildResults(false)))
+	assert.Nil(store.Record(BuildInfo{Commit: "c1", Branch: "main", BuildID: "2"}, buildResults(true)))
+	assert.Nil(store.Record(BuildInfo{Commit: "c2", Branch: "main", BuildID: "3"}, buildResults(false)))
+
+	records, err := store.Query(HistoryQuery{Commit: "c1"})
+	assert.Nil(err)
+	assert.Equal(4, len(records))
+
+	records, err = store.Query(HistoryQuery{Commit: "c1", BuildID: "2", Fullname: "A.Test-2"})
+	assert.Nil(err)
+	assert.Equal(1, len(records))
+	assert.Equal(Failure, records[0].Status)
+}
+
+func TestFileHistoryStoreCorrupted(t *testing.T) {
+	assert := a.New(t)
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	assert.Nil(os.WriteFile(path, []byte("{\"fullname\":\"A.Test-1\"}\n{broken\n{\"fullname\":\"A.Test-2\"}\n"), 0o644))
+
+	_, err := NewFileHistoryStore(path).Query(HistoryQuery{})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), path)
+	assert.Contains(err.Error(), "line 2")
+}
+
+func TestFileHistoryStoreInterruptedRecord(t *testing.T) {
+	assert := a.New(t)
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	assert.Nil(os.WriteFile(path, []byte("{\"fullname\":\"A.Test-1\"}\n{\"fullname\":\"A.Te"), 0o644))
+	store := NewFileHistoryStore(path)
+
+	records, err := store.Query(HistoryQuery{})
+	assert.Nil(err)
+	assert.Equal([]HistoryRecord{{Fullname: "A.Test-1"}}, records)
+
+	// the incomplete record is replaced by the next one
+	assert.Nil(store.Record(BuildInfo{Commit: "c1"}, buildResults(false)))
+	records, err = store.Query(HistoryQuery{})
+	assert.Nil(err)
+	assert.Equal(3, len(records))
+	assert.Equal("c1", records[1].Commit)
+}
@@ -0,0 +1,257 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// FromArchive reads the reports from a .zip, .tar, .tar.gz or .tgz archive, or a single gzipped report (.xml.gz),
+// as uploaded by CI systems. Reports are selected by the include and exclude patterns of the builder, matched
+// against the path within the archive or the name of the gzipped report. Suites are recorded with the filename
+// archive!/inner/path
+func (b *JUnitReportsReader) FromArchive(archive string) (TestResults, error) {
+	name := strings.ToLower(archive)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return b.fromZip(archive)
+	case strings.HasSuffix(name, ".tar"):
+		return b.fromTar(archive, false)
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return b.fromTar(archive, true)
+	case strings.HasSuffix(name, ".xml.gz"):
+		return b.fromGzip(archive)
+	default:
+		return nil, fmt.Errorf("unsupported archive %s, expected .zip, .tar, .tar.gz, .tgz or .xml.gz", archive)
+	}
+}
+
+func (b *JUnitReportsReader) fromZip(archive string) (TestResults, error) {
+	zipReader, err := zip.OpenReader(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer zipReader.Close()
+
+	files := make([]reportFile, 0)
+	err = fs.WalkDir(zipReader, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && b.selects(name) {
+			files = append(files, fsReportFile(zipReader, name, archiveFilename(archive, name)))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return b.fromReports(context.Background(), files)
+}
+
+// fromTar streams through a tar archive, parsing each selected report as it is read. As entries can only be
+// read in order, reports and output files are kept in memory until all of them were read when output files
+// are enabled
+func (b *JUnitReportsReader) fromTar(archive string, gzipped bool) (TestResults, error) {
+	file, err := os.Open(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if gzipped {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	if b.outputFiles {
+		return b.fromBufferedTar(archive, tar.NewReader(reader))
+	}
+
+	return b.fromStreamedTar(archive, tar.NewReader(reader))
+}
+
+// tarReport is a report parsed while streaming through a tar archive
+type tarReport struct {
+	name   string
+	report surefireReports
+	err    *ParseError
+}
+
+// fromStreamedTar parses the selected reports one after another, without holding them in memory
+func (b *JUnitReportsReader) fromStreamedTar(archive string, tarReader *tar.Reader) (TestResults, error) {
+	parsed := make([]tarReport, 0)
+	err := walkTar(tarReader, func(name string) error {
+		if !b.selects(name) {
+			return nil
+		}
+		report, parseError := b.readReportFile(context.Background(), reportFile{
+			name:   archiveFilename(archive, name),
+			module: moduleOf(name),
+			open: func() (io.ReadCloser, error) {
+				return io.NopCloser(tarReader), nil
+			},
+		})
+		parsed = append(parsed, tarReport{name: name, report: report, err: parseError})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(parsed, func(a, b tarReport) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	reports := make([]surefireReports, len(parsed))
+	fileErrors := make([]*ParseError, len(parsed))
+	for i, report := range parsed {
+		reports[i], fileErrors[i] = report.report, report.err
+	}
+	joined, err := b.joinReports(reports, fileErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.fromParsedReports(joined), nil
+}
+
+// fromBufferedTar keeps the selected reports and all output files in memory, so output files can be read
+// regardless of whether they are stored before or after their report
+func (b *JUnitReportsReader) fromBufferedTar(archive string, tarReader *tar.Reader) (TestResults, error) {
+	entries := make(map[string][]byte)
+	reports := make([]string, 0)
+	err := walkTar(tarReader, func(name string) error {
+		selected := b.selects(name)
+		if !selected && !strings.HasSuffix(name, "-output.txt") {
+			return nil
+		}
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			return err
+		}
+		entries[name] = content
+		if selected {
+			reports = append(reports, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(reports)
+
+	files := make([]reportFile, len(reports))
+	for i, name := range reports {
+		files[i] = memoryReportFile(entries, name, archiveFilename(archive, name))
+	}
+
+	return b.fromReports(context.Background(), files)
+}
+
+// walkTar calls visit with the cleaned name of each regular file of a tar archive, while its content can be read
+func walkTar(tarReader *tar.Reader, visit func(name string) error) error {
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := visit(path.Clean(strings.TrimPrefix(header.Name, "./"))); err != nil {
+			return err
+		}
+	}
+}
+
+// fromGzip reads a single gzipped report, if selected by the include and exclude patterns
+func (b *JUnitReportsReader) fromGzip(archive string) (TestResults, error) {
+	inner := strings.TrimSuffix(filepath.Base(archive), filepath.Ext(archive))
+	if !b.selects(inner) {
+		return b.fromReports(context.Background(), []reportFile{})
+	}
+
+	return b.fromReports(context.Background(), []reportFile{{
+		name: archiveFilename(archive, inner),
+		open: func() (io.ReadCloser, error) {
+			file, err := os.Open(archive)
+			if err != nil {
+				return nil, err
+			}
+			gzipReader, err := gzip.NewReader(file)
+			if err != nil {
+				file.Close()
+				return nil, err
+			}
+			return gzipFile{Reader: gzipReader, file: file}, nil
+		},
+	}})
+}
+
+// gzipFile closes the gzip reader and the underlying file
+type gzipFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g gzipFile) Close() error {
+	readerError := g.Reader.Close()
+	if err := g.file.Close(); err != nil {
+		return err
+	}
+	return readerError
+}
+
+// memoryReportFile is a report held in memory along with the files of its directory
+func memoryReportFile(entries map[string][]byte, name string, filename string) reportFile {
+	return reportFile{
+		name:   filename,
+		module: moduleOf(name),
+		open: func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(entries[name])), nil
+		},
+		openSibling: func(sibling string) (io.ReadCloser, error) {
+			content, ok := entries[path.Join(path.Dir(name), sibling)]
+			if !ok {
+				return nil, fs.ErrNotExist
+			}
+			return io.NopCloser(bytes.NewReader(content)), nil
+		},
+	}
+}
+
+// archiveFilename is the filename recorded for an entry of an archive
+func archiveFilename(archive string, name string) string {
+	return archive + "!/" + name
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"testing/fstest"
+
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestFromZipArchive(t *testing.T) {
+	assert := a.New(t)
+	archive := filepath.Join(t.TempDir(), "reports.zip")
+	writeZip(t, archive, sampleFS(t))
+
+	testResults, err := NewJUnitReportsReaderBuilder().WithOutputFiles().Build().FromArchive(archive)
+	assert.Nil(err)
+	assert.Equal(2, len(testResults.TestSuites()))
+
+	another := suiteByName("org.example.AnotherIT", testResults.TestSuites())
+	assert.Equal(archive+"!/services/api/target/failsafe-reports/TEST-org.example.AnotherIT.xml", another.Filename())
+	assert.Equal("services/api", another.Module())
+	assert.Equal("redirected output", another.SystemOut())
+}
+
+func TestFromTarArchive(t *testing.T) {
+	assert := a.New(t)
+	for _, name := range []string{"reports.tar", "reports.tar.gz", "reports.tgz"} {
+		archive := filepath.Join(t.TempDir(), name)
+		writeTar(t, archive, sampleFS(t), name != "reports.tar")
+
+		testResults, err := NewJUnitReportsReaderBuilder().WithOutputFiles().Build().FromArchive(archive)
+		assert.Nil(err)
+		assert.Equal(2, len(testResults.TestSuites()))
+
+		another := suiteByName("org.example.AnotherIT", testResults.TestSuites())
+		assert.Equal(archive+"!/services/api/target/failsafe-reports/TEST-org.example.AnotherIT.xml", another.Filename())
+		assert.Equal("redirected output", another.SystemOut())
+	}
+}
+
+func TestFromStreamedTarArchive(t *testing.T) {
+	assert := a.New(t)
+	archive := filepath.Join(t.TempDir(), "reports.tgz")
+	writeTar(t, archive, sampleFS(t), true)
+
+	testResults, err := NewJUnitReportsReaderBuilder().Build().FromArchive(archive)
+	assert.Nil(err)
+	assert.Equal(2, len(testResults.TestSuites()))
+
+	another := suiteByName("org.example.AnotherIT", testResults.TestSuites())
+	assert.Equal(archive+"!/services/api/target/failsafe-reports/TEST-org.example.AnotherIT.xml", another.Filename())
+	assert.Equal("services/api", another.Module())
+	assert.Equal(6, len(another.TestCases()))
+	assert.NotContains(another.SystemOut(), "redirected output")
+
+	testResults, err = NewJUnitReportsReaderBuilder().WithExcludePatterns("*IT.xml").Build().FromArchive(archive)
+	assert.Nil(err)
+	assert.Nil(suiteByName("org.example.AnotherIT", testResults.TestSuites()))
+}
+
+func TestFromGzippedReport(t *testing.T) {
+	assert := a.New(t)
+	archive := filepath.Join(t.TempDir(), "TEST-org.example.AnotherIT.xml.gz")
+	writeGzip(t, archive, "./sample/TEST-org.example.AnotherIT.xml")
+
+	testResults, err := NewJUnitReportsReaderBuilder().Build().FromArchive(archive)
+	assert.Nil(err)
+	assert.Equal(1, len(testResults.TestSuites()))
+	assert.Equal(archive+"!/TEST-org.example.AnotherIT.xml", testResults.TestSuites()[0].Filename())
+
+	// the inner name is selected like any other report
+	testResults, err = NewJUnitReportsReaderBuilder().WithExcludePatterns("*IT.xml").Build().FromArchive(archive)
+	assert.Nil(err)
+	assert.Empty(testResults.TestSuites())
+
+	other := filepath.Join(t.TempDir(), "results.xml.gz")
+	writeGzip(t, other, "./sample/TEST-org.example.AnotherIT.xml")
+	testResults, err = NewJUnitReportsReaderBuilder().Build().FromArchive(other)
+	assert.Nil(err)
+	assert.Empty(testResults.TestSuites())
+}
+
+func TestFromUnsupportedArchive(t *testing.T) {
+	assert := a.New(t)
+	_, err := NewJUnitReportsReaderBuilder().Build().FromArchive("reports.rar")
+	assert.NotNil(err)
+
+	_, err = NewJUnitReportsReaderBuilder().Build().FromArchive("output.txt.gz")
+	assert.ErrorContains(err, "unsupported archive")
+}
+
+func writeGzip(t *testing.T, archive string, report string) {
+	content, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gzipWriter := gzip.NewWriter(file)
+	if _, err := gzipWriter.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeZip(t *testing.T, archive string, fsys fstest.MapFS) {
+	file, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zipWriter := zip.NewWriter(file)
+	for _, name := range sortedNames(fsys) {
+		writer, err := zipWriter.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write(fsys[name].Data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTar(t *testing.T, archive string, fsys fstest.MapFS, gzipped bool) {
+	file, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var writer io.WriteCloser = file
+	if gzipped {
+		writer = gzip.NewWriter(file)
+	}
+	tarWriter := tar.NewWriter(writer)
+	for _, name := range sortedNames(fsys) {
+		content := fsys[name].Data
+		header := &tar.Header{Name: "./" + name, Mode: 0o600, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if gzipped {
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func sortedNames(fsys fstest.MapFS) []string {
+	names := make([]string, 0, len(fsys))
+	for name := range fsys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
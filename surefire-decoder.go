@@ -0,0 +1,351 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// TruncatedReportType is the Issue type of the test case added to suites recovered from a truncated report
+const TruncatedReportType = "surefire.TruncatedReport"
+
+// truncatedSuiteName is the name of the suite added to a <testsuites> wrapper which was cut off between its suites
+const truncatedSuiteName = "truncated-report"
+
+// testcaseHandler receives every decoded test case along with the suite it belongs to. Returning false stops decoding
+type testcaseHandler func(suite *surefireTestsuite, testcase surefireTestcase) bool
+
+// errStopped is the cause reported when a testcaseHandler stopped decoding
+var errStopped = errors.New("decoding stopped")
+
+// collectTestcase adds each test case to its suite
+func collectTestcase(suite *surefireTestsuite, testcase surefireTestcase) bool {
+	suite.Testcases = append(suite.Testcases, testcase)
+	return true
+}
+
+// readReport parses xml content from given reader and returns the contained test suites or failsafe summary.
+// Documents with a <testsuites> root are expanded into their suites, other root elements are ignored
+func (b *JUnitReportsReader) readReport(reader io.Reader) (surefireReports, *ParseError) {
+	return b.decodeReport(reader, collectTestcase)
+}
+
+// decodeReport parses a report, passing each test case to handle
+func (b *JUnitReportsReader) decodeReport(reader io.Reader, handle testcaseHandler) (surefireReports, *ParseError) {
+	decoder := newDecoder(reader)
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return surefireReports{}, decodeError(decoder, err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "testsuite":
+			testsuite, _, err := b.decodeTestsuite(decoder, start, handle)
+			if err != nil {
+				return surefireReports{}, err
+			}
+			return surefireReports{Testsuites: flattenTestsuites([]surefireTestsuite{testsuite})}, nil
+		case "testsuites":
+			testsuites, _, err := b.decodeTestsuites(decoder, handle)
+			if err != nil {
+				return surefireReports{}, err
+			}
+			return surefireReports{Testsuites: testsuites.flatten()}, nil
+		case "failsafe-summary":
+			var summary surefireFailsafeSummary
+			if err := decoder.DecodeElement(&summary, &start); err != nil {
+				return surefireReports{}, decodeError(decoder, err)
+			}
+			return surefireReports{Summaries: []surefireFailsafeSummary{summary}}, nil
+		default:
+			return surefireReports{}, nil
+		}
+	}
+}
+
+// decodeTestsuites decodes the suites of a <testsuites> wrapper whose start element was already read.
+// It reports whether the stream was truncated and recovered
+func (b *JUnitReportsReader) decodeTestsuites(decoder *xml.Decoder, handle testcaseHandler) (surefireTestsuites, bool, *ParseError) {
+	var testsuites surefireTestsuites
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return b.recoverTestsuites(decoder, testsuites, err, handle)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "testsuite":
+				testsuite, truncated, err := b.decodeTestsuite(decoder, t, handle)
+				if err != nil {
+					return surefireTestsuites{}, false, err
+				}
+				testsuites.Testsuites = append(testsuites.Testsuites, testsuite)
+				if truncated {
+					return testsuites, true, nil
+				}
+			case "testsuites":
+				nested, truncated, err := b.decodeTestsuites(decoder, handle)
+				if err != nil {
+					return surefireTestsuites{}, false, err
+				}
+				testsuites.Nested = append(testsuites.Nested, nested)
+				if truncated {
+					return testsuites, true, nil
+				}
+			default:
+				if err := decoder.Skip(); err != nil {
+					return b.recoverTestsuites(decoder, testsuites, err, handle)
+				}
+			}
+		case xml.EndElement:
+			return testsuites, false, nil
+		}
+	}
+}
+
+// decodeTestsuite decodes a suite element by element. If the stream ends before the suite does and recovery
+// is enabled, the complete test cases are kept, the suite is marked incomplete and truncated is true
+func (b *JUnitReportsReader) decodeTestsuite(decoder *xml.Decoder, start xml.StartElement, handle testcaseHandler) (testsuite surefireTestsuite, truncated bool, parseError *ParseError) {
+	if err := decodeAttributes(start, &testsuite); err != nil {
+		return surefireTestsuite{}, false, decodeError(decoder, err)
+	}
+	complete := 0
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return b.recoverTestsuite(decoder, testsuite, complete, err, handle)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "testcase":
+				var testcase surefireTestcase
+				if testcase, err = b.decodeTestcase(decoder, t); err == nil {
+					complete++
+					if !handle(&testsuite, testcase) {
+						return surefireTestsuite{}, false, &ParseError{Err: errStopped}
+					}
+				}
+			case "properties":
+				var properties surefireProperties
+				err = decoder.DecodeElement(&properties, &t)
+				testsuite.Properties = append(testsuite.Properties, properties.Properties...)
+			case "system-out":
+				err = b.decodePayload(decoder, t, &testsuite.SystemOut)
+			case "system-err":
+				err = b.decodePayload(decoder, t, &testsuite.SystemErr)
+			case "testsuite":
+				var nested surefireTestsuite
+				nested, truncated, parseError = b.decodeTestsuite(decoder, t, handle)
+				if parseError != nil {
+					return surefireTestsuite{}, false, parseError
+				}
+				testsuite.Testsuites = append(testsuite.Testsuites, nested)
+				if truncated {
+					return testsuite, true, nil
+				}
+			default:
+				err = decoder.Skip()
+			}
+			if err != nil {
+				return b.recoverTestsuite(decoder, testsuite, complete, err, handle)
+			}
+		case xml.EndElement:
+			return testsuite, false, nil
+		}
+	}
+}
+
+// decodeTestcase decodes a test case. When payloads are dropped, only the attributes of the test case and
+// its issues are decoded, stack traces and output are skipped without being materialized
+func (b *JUnitReportsReader) decodeTestcase(decoder *xml.Decoder, start xml.StartElement) (surefireTestcase, error) {
+	var testcase surefireTestcase
+	if !b.dropPayloads {
+		err := decoder.DecodeElement(&testcase, &start)
+		return testcase, err
+	}
+
+	if err := decodeAttributes(start, &testcase); err != nil {
+		return surefireTestcase{}, err
+	}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return surefireTestcase{}, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "skipped":
+				testcase.Skipped = &surefireSkipped{}
+				err = decodeAttributes(t, testcase.Skipped)
+			case "failure":
+				testcase.Failure = &surefireProblem{}
+				err = decodeAttributes(t, testcase.Failure)
+			case "error":
+				testcase.Error = &surefireProblem{}
+				err = decodeAttributes(t, testcase.Error)
+			case "rerunError":
+				testcase.ReRunErrors, err = appendRerun(testcase.ReRunErrors, t)
+			case "rerunFailure":
+				testcase.ReRunFailures, err = appendRerun(testcase.ReRunFailures, t)
+			case "flakyError":
+				testcase.FlakyError, err = appendRerun(testcase.FlakyError, t)
+			case "flakyFailure":
+				testcase.FlakyFailure, err = appendRerun(testcase.FlakyFailure, t)
+			}
+			if err == nil {
+				err = decoder.Skip()
+			}
+			if err != nil {
+				return surefireTestcase{}, err
+			}
+		case xml.EndElement:
+			return testcase, nil
+		}
+	}
+}
+
+// appendRerun appends a re-run decoded from the attributes of its start element
+func appendRerun(reruns []surefireRerun, start xml.StartElement) ([]surefireRerun, error) {
+	var rerun surefireRerun
+	if err := decodeAttributes(start, &rerun); err != nil {
+		return reruns, err
+	}
+
+	return append(reruns, rerun), nil
+}
+
+// decodePayload decodes output of a suite, unless payloads are dropped
+func (b *JUnitReportsReader) decodePayload(decoder *xml.Decoder, start xml.StartElement, payload *string) error {
+	if b.dropPayloads {
+		return decoder.Skip()
+	}
+
+	return decoder.DecodeElement(payload, &start)
+}
+
+// recoverTestsuite keeps a suite whose element ended unexpectedly, if recovery is enabled. Reports which are
+// malformed rather than cut off are not recovered
+func (b *JUnitReportsReader) recoverTestsuite(decoder *xml.Decoder, testsuite surefireTestsuite, complete int, err error, handle testcaseHandler) (surefireTestsuite, bool, *ParseError) {
+	parseError := decodeError(decoder, err)
+	if !b.recoverTruncated || !endedUnexpectedly(err) {
+		return surefireTestsuite{}, false, parseError
+	}
+
+	testsuite.Incomplete = true
+	handle(&testsuite, truncationTestcase(testsuite.Name, err,
+		fmt.Sprintf("report ended unexpectedly at line %d after %d complete test cases, the forked JVM probably crashed",
+			parseError.Line, complete)))
+
+	return testsuite, true, nil
+}
+
+// recoverTestsuites keeps the complete suites of a <testsuites> wrapper which ended unexpectedly, if recovery is
+// enabled. As the suite which was cut off is unknown, the truncation is added as an incomplete suite of its own
+func (b *JUnitReportsReader) recoverTestsuites(decoder *xml.Decoder, testsuites surefireTestsuites, err error, handle testcaseHandler) (surefireTestsuites, bool, *ParseError) {
+	parseError := decodeError(decoder, err)
+	if !b.recoverTruncated || !endedUnexpectedly(err) {
+		return surefireTestsuites{}, false, parseError
+	}
+
+	truncated := surefireTestsuite{Name: truncatedSuiteName, Incomplete: true}
+	handle(&truncated, truncationTestcase(truncated.Name, err,
+		fmt.Sprintf("report ended unexpectedly at line %d after %d complete test suites, the forked JVM probably crashed",
+			parseError.Line, len(testsuites.Testsuites)+len(testsuites.Nested))))
+	testsuites.Testsuites = append(testsuites.Testsuites, truncated)
+
+	return testsuites, true, nil
+}
+
+// truncationTestcase is the test case in error added to a suite recovered from a truncated report
+func truncationTestcase(classname string, err error, message string) surefireTestcase {
+	return surefireTestcase{
+		Name:      "truncated-report",
+		Classname: classname,
+		Error: &surefireProblem{
+			Message: message,
+			Type:    TruncatedReportType,
+			Data:    err.Error(),
+		},
+	}
+}
+
+// endedUnexpectedly reports whether decoding failed because the stream ended before the document did
+func endedUnexpectedly(err error) bool {
+	var syntaxError *xml.SyntaxError
+	if errors.As(err, &syntaxError) {
+		return syntaxError.Msg == "unexpected EOF"
+	}
+
+	return errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// decodeAttributes decodes the attributes of an element into v, without consuming any content
+func decodeAttributes(start xml.StartElement, v any) error {
+	return xml.NewTokenDecoder(&tokens{start, start.End()}).Decode(v)
+}
+
+// tokens replays a fixed sequence of tokens
+type tokens []xml.Token
+
+func (t *tokens) Token() (xml.Token, error) {
+	if len(*t) == 0 {
+		return nil, io.EOF
+	}
+	token := (*t)[0]
+	*t = (*t)[1:]
+
+	return token, nil
+}
+
+// flatten returns all suites of the wrapper, including those of nested wrappers
+func (s surefireTestsuites) flatten() []surefireTestsuite {
+	suites := flattenTestsuites(s.Testsuites)
+	for _, nested := range s.Nested {
+		suites = append(suites, nested.flatten()...)
+	}
+
+	return suites
+}
+
+// flattenTestsuites lifts nested suites to the top level. A suite which only groups other suites is dropped
+func flattenTestsuites(testsuites []surefireTestsuite) []surefireTestsuite {
+	suites := make([]surefireTestsuite, 0, len(testsuites))
+	for _, suite := range testsuites {
+		nested := suite.Testsuites
+		suite.Testsuites = nil
+		if len(nested) == 0 || len(suite.Testcases) > 0 {
+			suites = append(suites, suite)
+		}
+		suites = append(suites, flattenTestsuites(nested)...)
+	}
+
+	return suites
+}
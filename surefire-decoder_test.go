@@ -0,0 +1,156 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestRecoverTruncatedReport(t *testing.T) {
+	assert := a.New(t)
+	content, err := os.ReadFile("./sample/TEST-org.example.AnotherIT.xml")
+	assert.Nil(err)
+	// cut within the <testcase name="failure1"> element
+	truncated := content[:bytes.Index(content, []byte(`<testcase name="failure1"`))+100]
+
+	_, err = NewJUnitReportsReaderBuilder().Build().
+		FromReaders(map[string]io.Reader{"TEST-org.example.AnotherIT.xml": bytes.NewReader(truncated)})
+	assert.NotNil(err)
+
+	testResults, err := NewJUnitReportsReaderBuilder().WithTruncationRecovery().Build().
+		FromReaders(map[string]io.Reader{"TEST-org.example.AnotherIT.xml": bytes.NewReader(truncated)})
+	assert.Nil(err)
+
+	suite := suiteByName("org.example.AnotherIT", testResults.TestSuites())
+	assert.NotNil(suite)
+	assert.True(suite.Incomplete())
+	assert.Equal(5, len(suite.TestCases()))
+	assert.NotNil(caseByName("flaky2", suite.TestCases()))
+	assert.Nil(caseByName("failure1", suite.TestCases()))
+
+	truncation := caseByName("truncated-report", suite.NonSuccessfulTestCases())
+	assert.NotNil(truncation)
+	assert.Equal(Error, truncation.Status)
+	assert.Equal(TruncatedReportType, truncation.Issue.Type)
+	assert.Contains(truncation.Issue.Message, "after 4 complete test cases")
+	assert.Equal(2, testResults.Errors())
+}
+
+func TestRecoverTruncatedTestsuites(t *testing.T) {
+	assert := a.New(t)
+	content, err := os.ReadFile("./sample/testsuites-aggregate.xml")
+	assert.Nil(err)
+	truncated := content[:bytes.Index(content, []byte(`<testsuite name="org.example.ThirdTest"`))]
+
+	testResults, err := NewJUnitReportsReaderBuilder().WithTruncationRecovery().Build().
+		FromReaders(map[string]io.Reader{"aggregate.xml": bytes.NewReader(truncated)})
+	assert.Nil(err)
+	assert.Equal(3, len(testResults.TestSuites()))
+	assert.False(suiteByName("org.example.FirstTest", testResults.TestSuites()).Incomplete())
+	assert.False(suiteByName("org.example.SecondTest", testResults.TestSuites()).Incomplete())
+
+	// the package suite only had nested suites, so it only carries the truncation
+	packageSuite := suiteByName("org.example", testResults.TestSuites())
+	assert.True(packageSuite.Incomplete())
+	assert.Equal(1, len(packageSuite.TestCases()))
+}
+
+func TestRecoverTruncatedTestsuitesWrapper(t *testing.T) {
+	assert := a.New(t)
+	content, err := os.ReadFile("./sample/testsuites-aggregate.xml")
+	assert.Nil(err)
+	for _, cut := range []string{`<testsuite name="org.example" time`, `<testsuite name="org.example" time="0.3">`} {
+		truncated := content[:bytes.Index(content, []byte(cut))+len(cut)]
+
+		_, err = NewJUnitReportsReaderBuilder().Build().
+			FromReaders(map[string]io.Reader{"aggregate.xml": bytes.NewReader(truncated)})
+		assert.NotNil(err)
+
+		testResults, err := NewJUnitReportsReaderBuilder().WithTruncationRecovery().Build().
+			FromReaders(map[string]io.Reader{"aggregate.xml": bytes.NewReader(truncated)})
+		assert.Nil(err, cut)
+		assert.Equal(1, testResults.Errors())
+
+		first := suiteByName("org.example.FirstTest", testResults.TestSuites())
+		assert.NotNil(first)
+		assert.False(first.Incomplete())
+		assert.Equal(2, len(first.TestCases()))
+	}
+
+	// cut between the suites of the wrapper, the truncation is a suite of its own
+	truncated := content[:bytes.Index(content, []byte(`<testsuite name="org.example" time`))+len("<testsuite name=")]
+	testResults, err := NewJUnitReportsReaderBuilder().WithTruncationRecovery().Build().
+		FromReaders(map[string]io.Reader{"aggregate.xml": bytes.NewReader(truncated)})
+	assert.Nil(err)
+	assert.Equal(2, len(testResults.TestSuites()))
+	suite := suiteByName("truncated-report", testResults.TestSuites())
+	assert.NotNil(suite)
+	assert.True(suite.Incomplete())
+	truncation := caseByName("truncated-report", suite.NonSuccessfulTestCases())
+	assert.Equal(TruncatedReportType, truncation.Issue.Type)
+	assert.Contains(truncation.Issue.Message, "after 1 complete test suites")
+}
+
+func TestRecoverTruncatedReportWithStrictCounters(t *testing.T) {
+	assert := a.New(t)
+	truncated := `<testsuite name="org.example.StrictTest" tests="3" errors="0" skipped="0" failures="0">
+  <testcase name="first" classname="org.example.StrictTest"/>
+  <testcase name="second" classname="org.example.StrictTest"/>
+  <testcase name="thi`
+
+	testResults, err := NewJUnitReportsReaderBuilder().WithStrictCounters().WithTruncationRecovery().Build().
+		FromReaders(map[string]io.Reader{"TEST-org.example.StrictTest.xml": strings.NewReader(truncated)})
+	assert.Nil(err)
+
+	suite := suiteByName("org.example.StrictTest", testResults.TestSuites())
+	assert.True(suite.Incomplete())
+	assert.Equal(3, len(suite.TestCases()))
+	assert.Empty(suite.Anomalies())
+}
+
+func TestCompleteReportIsNotRecovered(t *testing.T) {
+	assert := a.New(t)
+	testResults, err := NewJUnitReportsReaderBuilder().WithTruncationRecovery().Build().
+		FromReportFiles([]string{"./sample/TEST-org.example.AnotherIT.xml"})
+	assert.Nil(err)
+	assert.False(testResults.TestSuites()[0].Incomplete())
+	assert.Equal(6, testResults.Tests())
+}
+
+func TestMalformedReportIsNotRecovered(t *testing.T) {
+	assert := a.New(t)
+	content, err := os.ReadFile("./sample/TEST-org.example.AnotherIT.xml")
+	assert.Nil(err)
+	// a mismatched end tag within the <testcase name="failure1"> element, the report itself is complete
+	malformed := bytes.Replace(content, []byte("</failure>"), []byte("</oops>"), 1)
+	assert.NotEqual(content, malformed)
+
+	_, err = NewJUnitReportsReaderBuilder().WithTruncationRecovery().Build().
+		FromReaders(map[string]io.Reader{"TEST-org.example.AnotherIT.xml": bytes.NewReader(malformed)})
+	var parseError *ParseError
+	assert.ErrorAs(err, &parseError)
+	assert.Equal("TEST-org.example.AnotherIT.xml", parseError.File)
+	assert.NotContains(err.Error(), "unexpected EOF")
+}
+
+func TestRecoveryRequiresSuiteStart(t *testing.T) {
+	_, err := NewJUnitReportsReaderBuilder().WithTruncationRecovery().Build().
+		readReport(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?><testsui`))
+	a.New(t).NotNil(err)
+}
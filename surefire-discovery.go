@@ -0,0 +1,223 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// defaultIncludePatterns select the reports Surefire and Failsafe write
+var defaultIncludePatterns = []string{"TEST-*.xml", "failsafe-summary.xml"}
+
+// reportDirectories are the directories below target Maven writes reports to
+var reportDirectories = []string{"surefire-reports", "failsafe-reports"}
+
+// FromDirectory recursively reads the reports from all target/surefire-reports and target/failsafe-reports
+// directories below dir, e.g. of a multi-module Maven build. Reports are selected by the include and exclude
+// patterns of the builder. The module of each suite is its directory relative to dir
+func (b *JUnitReportsReader) FromDirectory(dir string) (TestResults, error) {
+	fsys := os.DirFS(dir)
+	reports, err := b.discoverReports(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]reportFile, len(reports))
+	for i, report := range reports {
+		files[i] = fsReportFile(fsys, report, filepath.Join(dir, filepath.FromSlash(report)))
+	}
+
+	return b.fromReports(context.Background(), files)
+}
+
+// FromGlob reads the reports matching any of the patterns. Besides the syntax of path.Match, a "**" segment
+// matches any amount of directories, e.g. "**/target/failsafe-reports/TEST-*.xml". Exclude patterns of the
+// builder are applied. The module of each suite is its directory relative to the leading directory of the pattern
+// without wildcards
+func (b *JUnitReportsReader) FromGlob(patterns ...string) (TestResults, error) {
+	files := make([]reportFile, 0)
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		base, rest := splitPattern(filepath.ToSlash(pattern))
+		fsys := os.DirFS(base)
+		matches, err := b.glob(fsys, rest)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			file := fsReportFile(fsys, match, filepath.Join(filepath.FromSlash(base), filepath.FromSlash(match)))
+			file.module = moduleOf(match)
+			if !seen[file.name] {
+				seen[file.name] = true
+				files = append(files, file)
+			}
+		}
+	}
+	slices.SortFunc(files, func(a, b reportFile) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	return b.fromReports(context.Background(), files)
+}
+
+// discoverReports walks fsys and returns the reports within Maven's report directories selected by
+// include and exclude patterns
+func (b *JUnitReportsReader) discoverReports(fsys fs.FS) ([]string, error) {
+	reports := make([]string, 0)
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if name != "." && strings.HasPrefix(entry.Name(), ".") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if isInReportDirectory(name) && b.selects(name) {
+			reports = append(reports, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+// glob returns the files of fsys matching pattern which are not excluded
+func (b *JUnitReportsReader) glob(fsys fs.FS, pattern string) ([]string, error) {
+	segments := strings.Split(pattern, "/")
+	// without "**" a pattern cannot match below the depth of its segments
+	bounded := !slices.Contains(segments, "**")
+	matches := make([]string, 0)
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if bounded && name != "." && strings.Count(name, "/")+1 >= len(segments) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if matchSegments(segments, strings.Split(name, "/")) && !b.excludes(name) {
+			matches = append(matches, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+// selects reports whether a report is matched by an include pattern and no exclude pattern
+func (b *JUnitReportsReader) selects(name string) bool {
+	includes := b.includePatterns
+	if len(includes) == 0 {
+		includes = defaultIncludePatterns
+	}
+
+	return matchAny(includes, name) && !b.excludes(name)
+}
+
+func (b *JUnitReportsReader) excludes(name string) bool {
+	return matchAny(b.excludePatterns, name)
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matchPattern(pattern, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchPattern matches a slash separated name. A pattern without a slash is matched against the base name only,
+// otherwise the pattern is matched segment by segment, with "**" matching any amount of segments
+func matchPattern(pattern string, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(name))
+		return matched
+	}
+
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern []string, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(pattern[0], name[0]); !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
+
+// splitPattern splits a slash separated pattern into the leading directory without wildcards and the remainder
+func splitPattern(pattern string) (string, string) {
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if strings.ContainsAny(segment, `*?[\`) {
+			base := strings.Join(segments[:i], "/")
+			if base == "" && i > 0 {
+				base = "/"
+			} else if base == "" {
+				base = "."
+			}
+			return base, strings.Join(segments[i:], "/")
+		}
+	}
+
+	return path.Dir(pattern), path.Base(pattern)
+}
+
+// isInReportDirectory reports whether a slash separated name is located in target/surefire-reports or
+// target/failsafe-reports
+func isInReportDirectory(name string) bool {
+	dir := path.Dir(name)
+	return slices.Contains(reportDirectories, path.Base(dir)) && path.Base(path.Dir(dir)) == "target"
+}
+
+// moduleOf returns the directory of the module a slash separated report name belongs to, which is the
+// directory containing target. Empty if the report is not located within Maven's report directories
+func moduleOf(name string) string {
+	if !isInReportDirectory(name) {
+		return ""
+	}
+
+	return path.Dir(path.Dir(path.Dir(name)))
+}
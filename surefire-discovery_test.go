@@ -0,0 +1,116 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestFromDirectory(t *testing.T) {
+	assert := a.New(t)
+	dir := multiModuleTree(t)
+
+	testResults, err := NewJUnitReportsReaderBuilder().Build().FromDirectory(dir)
+	assert.Nil(err)
+	assert.Equal(2, len(testResults.TestSuites()))
+	assert.Equal(1, len(testResults.FailsafeSummaries()))
+
+	another := suiteByName("org.example.AnotherIT", testResults.TestSuites())
+	assert.NotNil(another)
+	assert.Equal("services/api", another.Module())
+	assert.Equal(filepath.Join(dir, "services", "api", "target", "failsafe-reports", "TEST-org.example.AnotherIT.xml"), another.Filename())
+
+	skipping := suiteByName("org.example.SkippingSuiteIT", testResults.TestSuites())
+	assert.NotNil(skipping)
+	assert.Equal(".", skipping.Module())
+}
+
+func TestFromDirectoryWithPatterns(t *testing.T) {
+	assert := a.New(t)
+	dir := multiModuleTree(t)
+
+	testResults, err := NewJUnitReportsReaderBuilder().
+		WithIncludePatterns("TEST-*IT.xml").
+		WithExcludePatterns("services/**").
+		Build().FromDirectory(dir)
+	assert.Nil(err)
+	assert.Equal(1, len(testResults.TestSuites()))
+	assert.Empty(testResults.FailsafeSummaries())
+	assert.NotNil(suiteByName("org.example.SkippingSuiteIT", testResults.TestSuites()))
+}
+
+func TestFromGlob(t *testing.T) {
+	assert := a.New(t)
+	dir := multiModuleTree(t)
+
+	testResults, err := NewJUnitReportsReaderBuilder().Build().
+		FromGlob(filepath.Join(dir, "**", "target", "*-reports", "TEST-*.xml"))
+	assert.Nil(err)
+	assert.Equal(2, len(testResults.TestSuites()))
+	assert.Equal("services/api", suiteByName("org.example.AnotherIT", testResults.TestSuites()).Module())
+
+	// the module is the same as when read from the directory
+	fromDirectory, err := NewJUnitReportsReaderBuilder().Build().FromDirectory(dir)
+	assert.Nil(err)
+	assert.Equal(suiteByName("org.example.AnotherIT", fromDirectory.TestSuites()).Module(),
+		suiteByName("org.example.AnotherIT", testResults.TestSuites()).Module())
+
+	testResults, err = NewJUnitReportsReaderBuilder().WithExcludePatterns("TEST-*.Another*").Build().
+		FromGlob(filepath.Join(dir, "**", "TEST-*.xml"))
+	assert.Nil(err)
+	assert.Equal(4, len(testResults.TestSuites()))
+	assert.Nil(suiteByName("org.example.AnotherIT", testResults.TestSuites()))
+	assert.NotNil(suiteByName("org.example.FirstTest", testResults.TestSuites()))
+	assert.Equal("", suiteByName("org.example.FirstTest", testResults.TestSuites()).Module())
+}
+
+func TestMatchPattern(t *testing.T) {
+	assert := a.New(t)
+	assert.True(matchPattern("TEST-*.xml", "a/b/TEST-x.xml"))
+	assert.False(matchPattern("TEST-*.xml", "a/b/x.xml"))
+	assert.True(matchPattern("a/**", "a/b/TEST-x.xml"))
+	assert.True(matchPattern("**/b/*.xml", "a/b/TEST-x.xml"))
+	assert.True(matchPattern("**/b/*.xml", "b/TEST-x.xml"))
+	assert.False(matchPattern("a/*.xml", "a/b/TEST-x.xml"))
+}
+
+// multiModuleTree creates a Maven multi-module layout with sample reports
+func multiModuleTree(t *testing.T) string {
+	dir := t.TempDir()
+	copySample(t, "TEST-org.example.SkippingSuiteIT.xml", filepath.Join(dir, "target", "surefire-reports"))
+	copySample(t, "TEST-org.example.AnotherIT.xml", filepath.Join(dir, "services", "api", "target", "failsafe-reports"))
+	copySample(t, "failsafe-summary.xml", filepath.Join(dir, "services", "api", "target", "failsafe-reports"))
+	// not within Maven's report directories
+	copySample(t, "testsuites-aggregate.xml", filepath.Join(dir, "build", "TEST-aggregate.xml"))
+	return dir
+}
+
+func copySample(t *testing.T, sample string, target string) {
+	content, err := os.ReadFile(filepath.Join("sample", sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Ext(target) == "" {
+		target = filepath.Join(target, sample)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,267 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// invalidCharacterReplacement replaces character references to characters not allowed in XML 1.0
+const invalidCharacterReplacement = "&#xFFFD;"
+
+// windows1252 maps the bytes 0x80 to 0x9F of windows-1252, where it differs from ISO-8859-1
+var windows1252 = [32]rune{
+	'€', '�', '‚', 'ƒ', '„', '…', '†', '‡',
+	'ˆ', '‰', 'Š', '‹', 'Œ', '�', 'Ž', '�',
+	'�', '‘', '’', '“', '”', '•', '–', '—',
+	'˜', '™', 'š', '›', 'œ', '�', 'ž', 'Ÿ',
+}
+
+// newDecoder creates a decoder which understands the legacy encodings of older build agents and tolerates
+// characters not allowed in XML 1.0, as they appear in captured test output
+func newDecoder(reader io.Reader) *xml.Decoder {
+	decoder := xml.NewDecoder(newSanitizingReader(reader))
+	decoder.CharsetReader = charsetReader
+	return decoder
+}
+
+// charsetReader converts content in charset to UTF-8. Supported are ISO-8859-1, windows-1252 and US-ASCII
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "iso8859-1", "iso_8859-1", "latin1", "l1", "us-ascii", "ascii":
+		return &singleByteReader{source: input}, nil
+	case "windows-1252", "cp1252", "x-cp1252":
+		return &singleByteReader{source: input, windows1252: true}, nil
+	default:
+		return nil, fmt.Errorf("unsupported charset %s", charset)
+	}
+}
+
+// singleByteReader decodes ISO-8859-1 or windows-1252 to UTF-8
+type singleByteReader struct {
+	source      io.Reader
+	windows1252 bool
+	buffer      []byte
+	decoded     []byte
+}
+
+func (r *singleByteReader) Read(p []byte) (int, error) {
+	if len(r.decoded) == 0 {
+		if r.buffer == nil {
+			r.buffer = make([]byte, 4096)
+		}
+		n, err := r.source.Read(r.buffer)
+		for _, c := range r.buffer[:n] {
+			char := rune(c)
+			if r.windows1252 && c >= 0x80 && c <= 0x9F {
+				char = windows1252[c-0x80]
+			}
+			r.decoded = utf8.AppendRune(r.decoded, char)
+		}
+		if len(r.decoded) == 0 {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.decoded)
+	r.decoded = r.decoded[n:]
+	return n, nil
+}
+
+// sanitizingReader replaces control characters not allowed in XML 1.0 by '?' and character references to them
+// by U+FFFD. Character references are left untouched in CDATA sections, where they are plain text. Unless the
+// XML declaration names another encoding, invalid UTF-8 and other characters not allowed are replaced by U+FFFD
+type sanitizingReader struct {
+	source  *bufio.Reader
+	pending []byte
+	cdata   bool
+
+	// whether the encoding was taken from the XML declaration
+	sniffed bool
+	utf8    bool
+}
+
+func newSanitizingReader(reader io.Reader) *sanitizingReader {
+	return &sanitizingReader{source: bufio.NewReader(reader)}
+}
+
+// maxDeclarationLength is the amount of bytes searched for the encoding of the XML declaration
+const maxDeclarationLength = 256
+
+// xmlEncoding matches the encoding of an XML declaration
+var xmlEncoding = regexp.MustCompile(`^(?:\xef\xbb\xbf)?\s*<\?xml[^>]*?\sencoding\s*=\s*["']([^"']*)["']`)
+
+// sniff tells whether the content is UTF-8, which it is unless the XML declaration names another encoding
+func (r *sanitizingReader) sniff() bool {
+	declaration, _ := r.source.Peek(maxDeclarationLength)
+	match := xmlEncoding.FindSubmatch(declaration)
+	if match == nil {
+		return true
+	}
+	encoding := strings.ToLower(string(match[1]))
+
+	return encoding == "utf-8" || encoding == "utf8"
+}
+
+func (r *sanitizingReader) Read(p []byte) (int, error) {
+	if !r.sniffed {
+		r.sniffed, r.utf8 = true, r.sniff()
+	}
+
+	n := 0
+	for n < len(p) {
+		if len(r.pending) > 0 {
+			copied := copy(p[n:], r.pending)
+			r.pending = r.pending[copied:]
+			n += copied
+			continue
+		}
+		// only read ahead as long as no data is ready, so the decoder sees content as soon as it is available
+		if n > 0 && r.source.Buffered() == 0 {
+			break
+		}
+
+		if plain := r.plainPrefix(p[n:]); plain > 0 {
+			n += plain
+			continue
+		}
+		if r.utf8 {
+			if next, _ := r.source.Peek(1); len(next) == 1 && next[0] >= utf8.RuneSelf {
+				r.pending = r.nextRune()
+				continue
+			}
+		}
+
+		c, err := r.source.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+
+		switch {
+		case c < 0x20 && c != '\t' && c != '\n' && c != '\r':
+			c = '?'
+		case c == '<' && !r.cdata:
+			r.cdata = r.follows("![CDATA[")
+		case c == ']' && r.cdata:
+			r.cdata = !r.follows("]>")
+		case c == '&' && !r.cdata:
+			if length, ok := r.invalidReference(); ok {
+				_, _ = r.source.Discard(length)
+				r.pending = []byte(invalidCharacterReplacement[1:])
+			}
+		}
+		p[n] = c
+		n++
+	}
+
+	return n, nil
+}
+
+// plainPrefix copies the buffered bytes up to the first one which needs a closer look to p
+func (r *sanitizingReader) plainPrefix(p []byte) int {
+	buffered, _ := r.source.Peek(min(len(p), r.source.Buffered()))
+	plain := 0
+	for plain < len(buffered) {
+		c := buffered[plain]
+		if c < 0x20 || c == '<' || c == ']' || c == '&' {
+			break
+		}
+		if c >= utf8.RuneSelf && r.utf8 {
+			char, size := utf8.DecodeRune(buffered[plain:])
+			if char == utf8.RuneError || !isXMLCharacter(char) {
+				break
+			}
+			plain += size
+			continue
+		}
+		plain++
+	}
+	copy(p, buffered[:plain])
+	_, _ = r.source.Discard(plain)
+	return plain
+}
+
+// nextRune consumes the next character, which does not start with an ASCII byte, and returns it in UTF-8.
+// Invalid bytes and characters not allowed in XML 1.0 are returned as U+FFFD
+func (r *sanitizingReader) nextRune() []byte {
+	next, _ := r.source.Peek(1)
+	for size := 2; !utf8.FullRune(next) && size <= utf8.UTFMax; size++ {
+		if next, _ = r.source.Peek(size); len(next) < size {
+			break
+		}
+	}
+
+	char, size := utf8.DecodeRune(next)
+	encoded := bytes.Clone(next[:size])
+	_, _ = r.source.Discard(size)
+	if char == utf8.RuneError && size == 1 || !isXMLCharacter(char) {
+		return []byte(string(utf8.RuneError))
+	}
+
+	return encoded
+}
+
+// follows tells whether the next bytes equal s, without consuming them
+func (r *sanitizingReader) follows(s string) bool {
+	next, _ := r.source.Peek(len(s))
+	return string(next) == s
+}
+
+// maxReferenceLength is the longest character reference checked, longer ones are left to the decoder
+const maxReferenceLength = 16
+
+// invalidReference checks whether the next bytes form the rest of a character reference to a character not
+// allowed in XML 1.0, e.g. "#1;", and returns its length
+func (r *sanitizingReader) invalidReference() (int, bool) {
+	next, _ := r.source.Peek(maxReferenceLength)
+	end := bytes.IndexByte(next, ';')
+	if len(next) < 3 || next[0] != '#' || end < 2 {
+		return 0, false
+	}
+
+	digits, base := next[1:end], 10
+	if digits[0] == 'x' {
+		digits, base = digits[1:], 16
+	}
+	char, err := strconv.ParseUint(string(digits), base, 32)
+	if err != nil {
+		// the decoder reports malformed references
+		if !errors.Is(err, strconv.ErrRange) {
+			return 0, false
+		}
+	} else if isXMLCharacter(rune(char)) {
+		return 0, false
+	}
+
+	return end + 1, true
+}
+
+// isXMLCharacter tells whether char is allowed in XML 1.0
+func isXMLCharacter(char rune) bool {
+	return char == '\t' || char == '\n' || char == '\r' ||
+		char >= 0x20 && char <= 0xD7FF ||
+		char >= 0xE000 && char <= 0xFFFD ||
+		char >= 0x10000 && char <= 0x10FFFF
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	a "github.com/stretchr/testify/assert"
+)
+
+func readSingleReport(t *testing.T, content []byte) (TestResults, error) {
+	t.Helper()
+	return NewJUnitReportsReaderBuilder().Build().
+		FromReaders(map[string]io.Reader{"TEST-org.example.EncodedTest.xml": iotest.OneByteReader(bytes.NewReader(content))})
+}
+
+func TestReadLatin1Report(t *testing.T) {
+	assert := a.New(t)
+	content := []byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n" +
+		"<testsuite name=\"org.example.EncodedTest\">" +
+		"<testcase name=\"caf\xe9\"><failure message=\"expected \xbb1\xab\"/></testcase>" +
+		"</testsuite>")
+
+	testResults, err := readSingleReport(t, content)
+	assert.Nil(err)
+
+	testCase := caseByName("café", testResults.TestSuites()[0].TestCases())
+	assert.NotNil(testCase)
+	assert.Equal("expected »1«", testCase.Issue.Message)
+}
+
+func TestReadWindows1252Report(t *testing.T) {
+	assert := a.New(t)
+	content := []byte("<?xml version=\"1.0\" encoding=\"windows-1252\"?>\n" +
+		"<testsuite name=\"org.example.EncodedTest\">" +
+		"<testcase name=\"costs\"><system-out>\x93100 \x80\x94 \x85</system-out></testcase>" +
+		"</testsuite>")
+
+	testResults, err := readSingleReport(t, content)
+	assert.Nil(err)
+
+	testCase := caseByName("costs", testResults.TestSuites()[0].TestCases())
+	assert.NotNil(testCase)
+	assert.Equal("“100 €” …", testCase.SystemOut)
+}
+
+func TestReadUnsupportedCharset(t *testing.T) {
+	assert := a.New(t)
+	content := []byte("<?xml version=\"1.0\" encoding=\"EBCDIC-US\"?>\n<testsuite name=\"org.example.EncodedTest\"/>")
+
+	_, err := readSingleReport(t, content)
+	var parseError *ParseError
+	assert.ErrorAs(err, &parseError)
+	assert.Contains(parseError.Error(), "unsupported charset EBCDIC-US")
+}
+
+func TestSanitizeInvalidCharacters(t *testing.T) {
+	assert := a.New(t)
+	content := []byte("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+		"<testsuite name=\"org.example.EncodedTest\">" +
+		"<testcase name=\"colored\"><system-out><![CDATA[\x1b[31mred\x1b[0m &#1;]]></system-out></testcase>" +
+		"<testcase name=\"referenced\"><failure message=\"bell &#7; &#x1B; &#65; &#99999999999;\"/></testcase>" +
+		"</testsuite>")
+
+	testResults, err := readSingleReport(t, content)
+	assert.Nil(err)
+
+	testCases := testResults.TestSuites()[0].TestCases()
+	assert.Equal("?[31mred?[0m &#1;", caseByName("colored", testCases).SystemOut)
+	assert.Equal("bell � � A �", caseByName("referenced", testCases).Issue.Message)
+}
+
+func TestSanitizeMalformedReferences(t *testing.T) {
+	assert := a.New(t)
+	for _, reference := range []string{"&#;", "&#x;", "&#12a;"} {
+		sanitized, err := io.ReadAll(newSanitizingReader(strings.NewReader("<a>" + reference + "</a>")))
+		assert.Nil(err)
+		assert.Equal("<a>"+reference+"</a>", string(sanitized))
+	}
+}
+
+func TestSanitizeInvalidUTF8(t *testing.T) {
+	assert := a.New(t)
+	content := []byte("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+		"<testsuite name=\"org.example.EncodedTest\">" +
+		"<testcase name=\"caf\xc3\xa9\"><system-out>caf\xe9 \xf0\x9f\x98\x80 \xc3</system-out>" +
+		"<failure message=\"r\xe9sum\xe9\"><![CDATA[at caf\xe9]]></failure></testcase>" +
+		"</testsuite>")
+
+	testResults, err := readSingleReport(t, content)
+	assert.Nil(err)
+
+	testCase := caseByName("café", testResults.TestSuites()[0].TestCases())
+	assert.NotNil(testCase)
+	assert.Equal("caf� 😀 �", testCase.SystemOut)
+	assert.Equal("r�sum�", testCase.Issue.Message)
+	assert.Equal("at caf�", testCase.Issue.Detail)
+}
+
+func TestSanitizeNonCharacters(t *testing.T) {
+	assert := a.New(t)
+	// U+FFFE and U+FFFF written as UTF-8, without XML declaration
+	content := []byte("<testsuite name=\"org.example.EncodedTest\">" +
+		"<testcase name=\"noncharacters\"><system-out>a\xef\xbf\xbeb\xef\xbf\xbfc</system-out></testcase>" +
+		"</testsuite>")
+
+	testResults, err := readSingleReport(t, content)
+	assert.Nil(err)
+	assert.Equal("a�b�c", caseByName("noncharacters", testResults.TestSuites()[0].TestCases()).SystemOut)
+
+	// declared single byte encodings are left to the charset reader
+	sanitized, err := io.ReadAll(newSanitizingReader(strings.NewReader("<?xml version='1.0' encoding='ISO-8859-1'?><a>\xe9</a>")))
+	assert.Nil(err)
+	assert.Equal("<?xml version='1.0' encoding='ISO-8859-1'?><a>\xe9</a>", string(sanitized))
+}
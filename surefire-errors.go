@@ -0,0 +1,51 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// ParseError describes why a single report file could not be read
+type ParseError struct {
+	// File the error occurred in
+	File string
+
+	// Line the error occurred at, 0 if the error is not related to the content of the file
+	Line int
+
+	// Offset in bytes the error occurred at
+	Offset int64
+
+	// The underlying error
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("error reading report %s: %v", e.File, e.Err)
+	}
+
+	return fmt.Sprintf("error reading report %s at line %d (offset %d): %v", e.File, e.Line, e.Offset, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// decodeError is a ParseError at the current position of the decoder
+func decodeError(decoder *xml.Decoder, err error) *ParseError {
+	line, _ := decoder.InputPos()
+	return &ParseError{Line: line, Offset: decoder.InputOffset(), Err: err}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestParseErrors(t *testing.T) {
+	assert := a.New(t)
+	broken := writeBrokenReport(t)
+
+	testResults, err := NewJUnitReportsReaderBuilder().Build().FromReportFiles([]string{
+		"./sample/TEST-org.example.AnotherIT.xml",
+		broken,
+		"./sample/does-not-exist.xml",
+	})
+	assert.Nil(testResults)
+
+	var parseError *ParseError
+	assert.True(errors.As(err, &parseError))
+	assert.Equal(broken, parseError.File)
+	assert.Equal(3, parseError.Line)
+	assert.Greater(parseError.Offset, int64(0))
+	assert.ErrorIs(err, fs.ErrNotExist)
+	assert.Contains(err.Error(), "does-not-exist.xml")
+}
+
+func TestLenientParsing(t *testing.T) {
+	assert := a.New(t)
+	broken := writeBrokenReport(t)
+
+	testResults, err := NewJUnitReportsReaderBuilder().WithLenientParsing().Build().FromReportFiles([]string{
+		"./sample/TEST-org.example.AnotherIT.xml",
+		broken,
+		"./sample/does-not-exist.xml",
+	})
+	assert.Nil(err)
+	assert.Equal(1, len(testResults.TestSuites()))
+	assert.Equal(2, len(testResults.SkippedFiles()))
+	assert.Equal(broken, testResults.SkippedFiles()[0].File)
+	assert.Equal(3, testResults.SkippedFiles()[0].Line)
+	assert.Equal("./sample/does-not-exist.xml", testResults.SkippedFiles()[1].File)
+	assert.ErrorIs(testResults.SkippedFiles()[1], fs.ErrNotExist)
+}
+
+func writeBrokenReport(t *testing.T) string {
+	broken := filepath.Join(t.TempDir(), "TEST-org.example.BrokenTest.xml")
+	err := os.WriteFile(broken, []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="org.example.BrokenTest" time="0.1" tests="1">
+  <testcase name="broken" classname="org.example.BrokenTest" time="0.1"></testcas>
+</testsuite>`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return broken
+}
@@ -12,21 +12,66 @@ governing permissions and limitations under the License.
 
 package surefire
 
-import (
-	"encoding/xml"
-)
+// surefireReports is everything read from a set of report files
+type surefireReports struct {
+	Testsuites []surefireTestsuite
+	Summaries  []surefireFailsafeSummary
+	// Files skipped in lenient mode
+	Skipped []*ParseError
+}
+
+// surefireFailsafeSummary encapsulates the failsafe-summary.xml written by the Failsafe plugin
+type surefireFailsafeSummary struct {
+	Result         string `xml:"result,attr"`
+	Timeout        bool   `xml:"timeout,attr"`
+	Completed      int    `xml:"completed"`
+	Errors         int    `xml:"errors"`
+	Failures       int    `xml:"failures"`
+	Skipped        int    `xml:"skipped"`
+	FailureMessage string `xml:"failureMessage"`
+	Filename       string
+}
+
+// surefireTestsuites wraps multiple test suites, as written by Gradle and other JUnit compatible tools
+type surefireTestsuites struct {
+	Testsuites []surefireTestsuite  `xml:"testsuite"`
+	Nested     []surefireTestsuites `xml:"testsuites"`
+}
 
-// surefireTestsuite encapsulates the data from a single test suite
+// surefireTestsuite encapsulates the data from a single test suite. Declared counters are nil if the attribute is missing
 type surefireTestsuite struct {
-	Suite     xml.Name           `xml:"testsuite"`
-	Name      string             `xml:"name,attr"`
-	Time      float64            `xml:"time,attr"`
-	Tests     int                `xml:"tests,attr"`
-	Errors    int                `xml:"errors,attr"`
-	Skipped   int                `xml:"skipped,attr"`
-	Failures  int                `xml:"failures,attr"`
-	Testcases []surefireTestcase `xml:"testcase"`
-	Filename  string
+	Name       string             `xml:"name,attr"`
+	Time       float64            `xml:"time,attr"`
+	Timestamp  string             `xml:"timestamp,attr"`
+	Hostname   string             `xml:"hostname,attr"`
+	ID         string             `xml:"id,attr"`
+	Package    string             `xml:"package,attr"`
+	Version    string             `xml:"version,attr"`
+	Tests      *int               `xml:"tests,attr"`
+	Errors     *int               `xml:"errors,attr"`
+	Skipped    *int               `xml:"skipped,attr"`
+	Failures   *int               `xml:"failures,attr"`
+	Properties []surefireProperty `xml:"properties>property"`
+	Testcases  []surefireTestcase `xml:"testcase"`
+	SystemOut  string             `xml:"system-out"`
+	SystemErr  string             `xml:"system-err"`
+	// Nested suites, some tools group suites by package this way
+	Testsuites []surefireTestsuite `xml:"testsuite"`
+	Filename   string
+	Module     string
+	// Set if the report ended before the suite did
+	Incomplete bool
+}
+
+// surefireProperties wraps the properties of a suite
+type surefireProperties struct {
+	Properties []surefireProperty `xml:"property"`
+}
+
+// surefireProperty is a single system property of the JVM which ran the suite
+type surefireProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
 }
 
 // surefireTestcase encapsulates the data from a single test case
@@ -41,22 +86,27 @@ type surefireTestcase struct {
 	ReRunFailures []surefireRerun  `xml:"rerunFailure"`
 	FlakyError    []surefireRerun  `xml:"flakyError"`
 	FlakyFailure  []surefireRerun  `xml:"flakyFailure"`
+	SystemOut     string           `xml:"system-out"`
+	SystemErr     string           `xml:"system-err"`
 }
 
 // surefireSkipped is present if the referencing test case was skipped
 type surefireSkipped struct {
 	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
 }
 
 // surefireProblem is present if the referencing test case failed or errored
 type surefireProblem struct {
 	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
 	Data    string `xml:",chardata"`
 }
 
 // surefireRerun represents errors, failures and flakes from re-runs
 type surefireRerun struct {
 	Message     string `xml:"message,attr"`
+	Type        string `xml:"type,attr"`
 	Stacktrace  string `xml:"stackTrace"`
 	SystemOut   string `xml:"system-out"`
 	SystemError string `xml:"system-err"`
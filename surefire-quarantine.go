@@ -0,0 +1,138 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// QuarantineEntry quarantines the test cases whose Fullname matches a pattern
+type QuarantineEntry struct {
+	// Pattern of full qualified test case names, in the syntax of path.Match, e.g. org.example.AnotherIT.flaky*
+	Pattern string
+
+	// Who takes care of the quarantined test cases
+	Owner string
+
+	// Ticket tracking the quarantined test cases
+	Ticket string
+
+	// When the entry expires, zero if it never does
+	Expires time.Time
+}
+
+// quarantineFileEntry is an entry as written to a quarantine file
+type quarantineFileEntry struct {
+	Pattern string `yaml:"pattern"`
+	Owner   string `yaml:"owner"`
+	Ticket  string `yaml:"ticket"`
+	Expires string `yaml:"expires"`
+}
+
+// LoadQuarantine reads the quarantine entries of a YAML or JSON file, see ReadQuarantine
+func LoadQuarantine(file string) ([]QuarantineEntry, error) {
+	reader, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	entries, err := ReadQuarantine(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error reading quarantine %s: %w", file, err)
+	}
+
+	return entries, nil
+}
+
+// ReadQuarantine reads quarantine entries given as a YAML or JSON list of objects with the fields pattern, owner,
+// ticket and expires. An entry expires after the day given as 2006-01-02 in UTC, or at the time given in RFC 3339
+func ReadQuarantine(reader io.Reader) ([]QuarantineEntry, error) {
+	var fileEntries []quarantineFileEntry
+	if err := yaml.NewDecoder(reader).Decode(&fileEntries); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	entries := make([]QuarantineEntry, 0, len(fileEntries))
+	for _, fileEntry := range fileEntries {
+		if _, err := path.Match(fileEntry.Pattern, ""); err != nil || fileEntry.Pattern == "" {
+			return nil, fmt.Errorf("invalid quarantine pattern %q", fileEntry.Pattern)
+		}
+		expires, err := toExpiry(fileEntry.Expires)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expiry of quarantine pattern %q: %w", fileEntry.Pattern, err)
+		}
+		entries = append(entries, QuarantineEntry{
+			Pattern: fileEntry.Pattern,
+			Owner:   fileEntry.Owner,
+			Ticket:  fileEntry.Ticket,
+			Expires: expires,
+		})
+	}
+
+	return entries, nil
+}
+
+// toExpiry parses the expiry of a quarantine entry. A day expires at its end
+func toExpiry(expires string) (time.Time, error) {
+	if expires == "" {
+		return time.Time{}, nil
+	}
+	if day, err := time.Parse(time.DateOnly, expires); err == nil {
+		return day.AddDate(0, 0, 1), nil
+	}
+
+	return time.Parse(time.RFC3339, expires)
+}
+
+// Expired tells whether the entry expired at given time
+func (e QuarantineEntry) Expired(at time.Time) bool {
+	return !e.Expires.IsZero() && !at.Before(e.Expires)
+}
+
+// Matches tells whether the entry quarantines the test case of given full qualified name
+func (e QuarantineEntry) Matches(fullname string) bool {
+	matched, _ := path.Match(e.Pattern, fullname)
+	return matched
+}
+
+// quarantineEntry returns the entry which quarantines the test case of given full qualified name, nil if none does
+func (b *JUnitReportsReader) quarantineEntry(fullname string) *QuarantineEntry {
+	now := time.Now()
+	for i := range b.quarantine {
+		if !b.quarantine[i].Expired(now) && b.quarantine[i].Matches(fullname) {
+			return &b.quarantine[i]
+		}
+	}
+
+	return nil
+}
+
+// expiredQuarantine returns the expired quarantine entries
+func (b *JUnitReportsReader) expiredQuarantine() []QuarantineEntry {
+	now := time.Now()
+	expired := make([]QuarantineEntry, 0)
+	for _, entry := range b.quarantine {
+		if entry.Expired(now) {
+			expired = append(expired, entry)
+		}
+	}
+
+	return expired
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	a "github.com/stretchr/testify/assert"
+)
+
+const quarantineYAML = `
+- pattern: org.example.AnotherIT.failure*
+  owner: team-a
+  ticket: PROJ-1
+  expires: 2999-12-31
+- pattern: org.example.AnotherIT.error1
+  owner: team-b
+  ticket: PROJ-2
+  expires: 2000-01-01
+- pattern: org.example.AnotherIT.success
+  owner: team-c
+`
+
+func TestQuarantineFailingTests(t *testing.T) {
+	assert := a.New(t)
+	quarantine, err := ReadQuarantine(strings.NewReader(quarantineYAML))
+	assert.Nil(err)
+	assert.Equal(3, len(quarantine))
+	assert.Equal(time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC), quarantine[0].Expires)
+	assert.True(quarantine[2].Expires.IsZero())
+
+	testResults, err := NewJUnitReportsReaderBuilder().WithQuarantine(quarantine...).Build().
+		FromReportFiles([]string{"./sample/TEST-org.example.AnotherIT.xml"})
+	assert.Nil(err)
+
+	assert.Equal(6, testResults.Tests())
+	assert.Equal(0, testResults.Failures())
+	assert.Equal(1, testResults.Errors())
+	assert.Equal(1, testResults.Quarantined())
+	assert.Equal([]QuarantineEntry{quarantine[1]}, testResults.ExpiredQuarantine())
+
+	suite := suiteByName("org.example.AnotherIT", testResults.TestSuites())
+	assert.NotNil(suite)
+	assert.Equal(1, suite.Quarantined())
+
+	failure := caseByName("failure1", suite.TestCases())
+	assert.Equal(Quarantined, failure.Status)
+	assert.NotNil(failure.Issue)
+	assert.Equal("PROJ-1", failure.Quarantine.Ticket)
+
+	// expired entries no longer quarantine, succeeding tests are left alone
+	assert.Equal(Error, caseByName("error1", suite.TestCases()).Status)
+	success := caseByName("success", suite.TestCases())
+	assert.Equal(Success, success.Status)
+	assert.Nil(success.Quarantine)
+	assert.Empty(success.QuarantinedStatus)
+	assert.Equal(Failure, failure.QuarantinedStatus)
+	assert.Equal(Failure, failure.Outcome())
+}
+
+func TestQuarantineKeepsSummaryCounters(t *testing.T) {
+	assert := a.New(t)
+	files := []string{"./sample/failsafe-summary.xml", "./sample/TEST-org.example.AnotherIT.xml"}
+	unquarantined, err := NewJUnitReportsReaderBuilder().Build().FromReportFiles(files)
+	assert.Nil(err)
+	quarantined, err := NewJUnitReportsReaderBuilder().WithQuarantine(QuarantineEntry{Pattern: "org.example.AnotherIT.*"}).
+		Build().FromReportFiles(files)
+	assert.Nil(err)
+
+	assert.Equal(2, quarantined.Quarantined())
+	assert.Equal(unquarantined.SummaryMismatches(), quarantined.SummaryMismatches())
+}
+
+func TestLoadQuarantineJSON(t *testing.T) {
+	assert := a.New(t)
+	file := filepath.Join(t.TempDir(), "quarantine.json")
+	assert.Nil(os.WriteFile(file, []byte(`[
+		{"pattern": "org.example.*IT.flaky?", "owner": "team-a", "ticket": "PROJ-3", "expires": "2024-03-01T12:00:00Z"}
+	]`), 0o644))
+
+	quarantine, err := LoadQuarantine(file)
+	assert.Nil(err)
+	assert.Equal([]QuarantineEntry{{
+		Pattern: "org.example.*IT.flaky?",
+		Owner:   "team-a",
+		Ticket:  "PROJ-3",
+		Expires: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+	}}, quarantine)
+	assert.True(quarantine[0].Matches("org.example.AnotherIT.flaky1"))
+	assert.False(quarantine[0].Matches("org.example.AnotherIT.flakyError"))
+	assert.False(quarantine[0].Expired(time.Date(2024, 3, 1, 11, 59, 0, 0, time.UTC)))
+	assert.True(quarantine[0].Expired(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestReadInvalidQuarantine(t *testing.T) {
+	assert := a.New(t)
+	_, err := ReadQuarantine(strings.NewReader("- pattern: org.example.[\n"))
+	assert.ErrorContains(err, "invalid quarantine pattern")
+
+	_, err = ReadQuarantine(strings.NewReader("- pattern: org.example.*\n  expires: tomorrow\n"))
+	assert.ErrorContains(err, "invalid expiry")
+
+	quarantine, err := ReadQuarantine(strings.NewReader(""))
+	assert.Nil(err)
+	assert.Empty(quarantine)
+}
@@ -13,106 +13,195 @@ governing permissions and limitations under the License.
 package surefire
 
 import (
-	"encoding/xml"
-	"fmt"
+	"context"
+	"errors"
 	"io"
-	"log/slog"
-	"os"
+	"io/fs"
+	"runtime"
+	"slices"
 	"sync"
 )
 
 type JUnitReportsReader struct {
-	labeler Labeler
+	labeler          Labeler
+	testCaseLabeler  TestCaseLabeler
+	propertyRedactor PropertyRedactor
+	outputFiles      bool
+	maxOutputSize    int
+	includePatterns  []string
+	excludePatterns  []string
+	concurrency      int
+	lenient          bool
+	recoverTruncated bool
+	dropPayloads     bool
+	strictCounters   bool
+	quarantine       []QuarantineEntry
 }
 
 func (b *JUnitReportsReader) FromReportFiles(surefireReportFiles []string) (TestResults, error) {
-	surefireSuites, err := parseSurefireReports(surefireReportFiles)
+	return b.FromReportFilesContext(context.Background(), surefireReportFiles)
+}
+
+// FromReportFilesContext reads the given files like FromReportFiles, but stops reading
+// when the context is cancelled or its deadline exceeded
+func (b *JUnitReportsReader) FromReportFilesContext(ctx context.Context, surefireReportFiles []string) (TestResults, error) {
+	return b.fromReports(ctx, osReportFiles(surefireReportFiles))
+}
+
+// fromReports reads the given reports and converts them. All entry points share this path
+func (b *JUnitReportsReader) fromReports(ctx context.Context, files []reportFile) (TestResults, error) {
+	reports, err := b.parseReports(ctx, files)
 	if err != nil {
 		return nil, err
 	}
 
-	return b.FromJUnitRepresentation(surefireSuites), nil
+	return b.fromParsedReports(reports), nil
+}
+
+// fromParsedReports converts reports which were already parsed
+func (b *JUnitReportsReader) fromParsedReports(reports surefireReports) TestResults {
+	testResults := b.toTestResults(reports.Testsuites)
+	testResults.addSummaries(reports.Summaries)
+	testResults.skippedFiles = reports.Skipped
+
+	return testResults
 }
 
 func (b *JUnitReportsReader) FromJUnitRepresentation(surefireSuites []surefireTestsuite) TestResults {
+	return b.toTestResults(surefireSuites)
+}
+
+func (b *JUnitReportsReader) toTestResults(surefireSuites []surefireTestsuite) *testResults {
 	testResults := testResults{}
 
 	for _, surefireSuite := range surefireSuites {
-		testSuite := testSuite{
-			name:      surefireSuite.Name,
-			filename:  surefireSuite.Filename,
-			time:      surefireSuite.Time,
-			testcases: make([]TestCase, 0),
-			successes: 0,
-			failures:  0,
-			errors:    0,
-			skipped:   0,
-			labels:    make([]string, 0),
-		}
+		testSuite := b.newTestSuite(surefireSuite)
 
 		for _, surefireTestCase := range surefireSuite.Testcases {
-			var issue *Issue
-			_failure := optionalTestProblem(surefireTestCase.Failure)
-			_error := optionalTestProblem(surefireTestCase.Error)
-
-			var status Status
-			var skipped *Skipped
-
-			_skipped := surefireTestCase.Skipped
-
-			if _skipped != nil {
-				testSuite.skipped++
-				skipped = &Skipped{Message: _skipped.Message}
-				status = Skip
-			} else if _failure != nil {
-				testSuite.failures++
-				issue = _failure
-				status = Failure
-			} else if _error != nil {
-				testSuite.errors++
-				issue = _error
-				status = Error
-			} else {
-				testSuite.successes++
-				status = Success
-			}
+			testSuite.testcases = append(testSuite.testcases, b.toTestCase(surefireTestCase, testSuite))
+		}
+		if b.labeler != nil {
+			testSuite.labels = b.labeler(testSuite)
+		}
+		for i := range testSuite.testcases {
+			b.labelTestCase(&testSuite.testcases[i])
+		}
+		testResults.append(testSuite)
+	}
+	testResults.expired = b.expiredQuarantine()
 
-			if amountOf(surefireTestCase.FlakyError)+amountOf(surefireTestCase.FlakyFailure) > 0 {
-				status = Flaky
-			}
+	return &testResults
+}
 
-			testCase := TestCase{
-				Name:      surefireTestCase.Name,
-				Classname: surefireTestCase.Classname,
-				Fullname:  surefireTestCase.Classname + "." + surefireTestCase.Name,
-				Suite:     &testSuite,
+// newTestSuite converts a suite without its test cases
+func (b *JUnitReportsReader) newTestSuite(surefireSuite surefireTestsuite) *testSuite {
+	return &testSuite{
+		name:       surefireSuite.Name,
+		filename:   surefireSuite.Filename,
+		module:     surefireSuite.Module,
+		incomplete: surefireSuite.Incomplete,
+		time:       surefireSuite.Time,
+		testcases:  make([]TestCase, 0),
+		successes:  0,
+		failures:   0,
+		errors:     0,
+		skipped:    0,
+		labels:     make([]string, 0),
 
-				Time:              surefireSuite.Time,
-				Issue:             issue,
-				Skipped:           skipped,
-				RerunErrors:       toReRunIssues(surefireTestCase.ReRunErrors),
-				AmountRerunErrors: amountOf(surefireTestCase.ReRunErrors),
+		properties: b.toProperties(surefireSuite.Properties),
+		systemOut:  b.truncateOutput(b.payload(surefireSuite.SystemOut)),
+		systemErr:  b.truncateOutput(b.payload(surefireSuite.SystemErr)),
+		anomalies:  suiteAnomalies(surefireSuite),
+		timestamp:  toTimestamp(surefireSuite.Timestamp),
+		hostname:   surefireSuite.Hostname,
+		id:         surefireSuite.ID,
+		pkg:        surefireSuite.Package,
+		version:    surefireSuite.Version,
+	}
+}
 
-				RerunFailures:       toReRunIssues(surefireTestCase.ReRunFailures),
-				AmountRerunFailures: amountOf(surefireTestCase.ReRunFailures),
+// labelTestCase assigns the labels of the test case labeler, each label once. Labels of its suite are already assigned
+func (b *JUnitReportsReader) labelTestCase(testCase *TestCase) {
+	if b.testCaseLabeler == nil {
+		return
+	}
 
-				FlakyErrors:       toReRunIssues(surefireTestCase.FlakyError),
-				AmountFlakyErrors: amountOf(surefireTestCase.FlakyError),
+	labels := make([]string, 0)
+	for _, label := range b.testCaseLabeler(*testCase) {
+		if !slices.Contains(labels, label) {
+			labels = append(labels, label)
+		}
+	}
+	testCase.Labels = labels
+}
 
-				FlakyFailures:       toReRunIssues(surefireTestCase.FlakyFailure),
-				AmountFlakyFailures: amountOf(surefireTestCase.FlakyFailure),
-				Status:              status,
-			}
+// toTestCase converts a test case of the given suite and updates the counters of that suite
+func (b *JUnitReportsReader) toTestCase(surefireTestCase surefireTestcase, testSuite *testSuite) TestCase {
+	var issue *Issue
+	_failure := b.optionalTestProblem(surefireTestCase.Failure)
+	_error := b.optionalTestProblem(surefireTestCase.Error)
 
-			testSuite.testcases = append(testSuite.testcases, testCase)
-		}
-		if b.labeler != nil {
-			testSuite.labels = b.labeler(&testSuite)
+	var status Status
+	var skipped *Skipped
+
+	_skipped := surefireTestCase.Skipped
+
+	fullname := surefireTestCase.Classname + "." + surefireTestCase.Name
+	var quarantine *QuarantineEntry
+	var quarantinedStatus Status
+
+	if _skipped != nil {
+		skipped = &Skipped{Message: _skipped.Message, Type: _skipped.Type}
+		status = Skip
+	} else if _failure != nil {
+		issue = _failure
+		status = Failure
+	} else if _error != nil {
+		issue = _error
+		status = Error
+	} else {
+		status = Success
+	}
+
+	if issue != nil {
+		if quarantine = b.quarantineEntry(fullname); quarantine != nil {
+			status, quarantinedStatus = Quarantined, status
 		}
-		testResults.append(&testSuite)
 	}
+	testSuite.count(status)
 
-	return &testResults
+	if amountOf(surefireTestCase.FlakyError)+amountOf(surefireTestCase.FlakyFailure) > 0 {
+		status = Flaky
+	}
+
+	return TestCase{
+		Name:      surefireTestCase.Name,
+		Classname: surefireTestCase.Classname,
+		Fullname:  fullname,
+		Suite:     testSuite,
+
+		Time:              surefireTestCase.Time,
+		Issue:             issue,
+		Skipped:           skipped,
+		RerunErrors:       b.toReRunIssues(surefireTestCase.ReRunErrors),
+		AmountRerunErrors: amountOf(surefireTestCase.ReRunErrors),
+
+		RerunFailures:       b.toReRunIssues(surefireTestCase.ReRunFailures),
+		AmountRerunFailures: amountOf(surefireTestCase.ReRunFailures),
+
+		FlakyErrors:       b.toReRunIssues(surefireTestCase.FlakyError),
+		AmountFlakyErrors: amountOf(surefireTestCase.FlakyError),
+
+		FlakyFailures:       b.toReRunIssues(surefireTestCase.FlakyFailure),
+		AmountFlakyFailures: amountOf(surefireTestCase.FlakyFailure),
+		Status:              status,
+
+		SystemOut: b.truncateOutput(b.payload(surefireTestCase.SystemOut)),
+		SystemErr: b.truncateOutput(b.payload(surefireTestCase.SystemErr)),
+
+		Quarantine:        quarantine,
+		QuarantinedStatus: quarantinedStatus,
+	}
 }
 
 type JUnitReportsReaderBuilder struct {
@@ -130,66 +219,240 @@ func (b *JUnitReportsReaderBuilder) WithLabeler(labeler Labeler) *JUnitReportsRe
 	return b
 }
 
+// WithTestCaseLabeler sets a labeler which assigns labels to each test case. Counters per label are exposed by
+// TestResults.Counts
+func (b *JUnitReportsReaderBuilder) WithTestCaseLabeler(labeler TestCaseLabeler) *JUnitReportsReaderBuilder {
+	b.JUnitReportsReader.testCaseLabeler = labeler
+	return b
+}
+
+// WithPropertyRedactor sets a redactor which filters suite properties before they are exposed, e.g. to hide user.home
+func (b *JUnitReportsReaderBuilder) WithPropertyRedactor(redactor PropertyRedactor) *JUnitReportsReaderBuilder {
+	b.JUnitReportsReader.propertyRedactor = redactor
+	return b
+}
+
+// WithOutputFiles enables reading the <ClassName>-output.txt files Surefire writes next to the reports
+// when redirectTestOutputToFile is set. Their content is added to the suite's system-out
+func (b *JUnitReportsReaderBuilder) WithOutputFiles() *JUnitReportsReaderBuilder {
+	b.JUnitReportsReader.outputFiles = true
+	return b
+}
+
+// WithMaxOutputSize truncates system-out and system-err of suites and test cases to the given amount of bytes
+func (b *JUnitReportsReaderBuilder) WithMaxOutputSize(bytes int) *JUnitReportsReaderBuilder {
+	b.JUnitReportsReader.maxOutputSize = bytes
+	return b
+}
+
+// WithIncludePatterns sets the patterns selecting reports when reading from a directory,
+// by default TEST-*.xml and failsafe-summary.xml. A pattern without a slash matches the file name,
+// otherwise the path relative to the directory, where "**" matches any amount of directories
+func (b *JUnitReportsReaderBuilder) WithIncludePatterns(patterns ...string) *JUnitReportsReaderBuilder {
+	b.JUnitReportsReader.includePatterns = patterns
+	return b
+}
+
+// WithExcludePatterns sets the patterns of reports which are not read from a directory or glob,
+// e.g. "legacy/**" to skip all reports of a module
+func (b *JUnitReportsReaderBuilder) WithExcludePatterns(patterns ...string) *JUnitReportsReaderBuilder {
+	b.JUnitReportsReader.excludePatterns = patterns
+	return b
+}
+
+// WithConcurrency limits the amount of report files read concurrently, by default the amount of CPUs
+func (b *JUnitReportsReaderBuilder) WithConcurrency(concurrency int) *JUnitReportsReaderBuilder {
+	b.JUnitReportsReader.concurrency = concurrency
+	return b
+}
+
+// WithLenientParsing skips report files which cannot be read instead of failing. The skipped files
+// are exposed by TestResults.SkippedFiles
+func (b *JUnitReportsReaderBuilder) WithLenientParsing() *JUnitReportsReaderBuilder {
+	b.JUnitReportsReader.lenient = true
+	return b
+}
+
+// WithTruncationRecovery salvages the complete test cases of reports which end abruptly, e.g. because a forked JVM
+// was killed while writing them. Such suites are marked incomplete and get an additional test case in error
+// describing the truncation. Reports which are malformed rather than cut off still fail parsing
+func (b *JUnitReportsReaderBuilder) WithTruncationRecovery() *JUnitReportsReaderBuilder {
+	b.JUnitReportsReader.recoverTruncated = true
+	return b
+}
+
+// WithoutPayloads drops stack traces, system-out and system-err of suites and test cases, which make up
+// most of large reports. Messages and types of issues are kept
+func (b *JUnitReportsReaderBuilder) WithoutPayloads() *JUnitReportsReaderBuilder {
+	b.JUnitReportsReader.dropPayloads = true
+	return b
+}
+
+// WithStrictCounters fails reading a report if a suite declares counters which disagree with its test cases.
+// Otherwise such suites are read and expose the discrepancies as anomalies
+func (b *JUnitReportsReaderBuilder) WithStrictCounters() *JUnitReportsReaderBuilder {
+	b.JUnitReportsReader.strictCounters = true
+	return b
+}
+
+// WithQuarantine marks failing and erroneous test cases matching one of the entries, which did not expire yet,
+// as Quarantined, so they do not count as failures or errors. Entries are read by LoadQuarantine
+func (b *JUnitReportsReaderBuilder) WithQuarantine(entries ...QuarantineEntry) *JUnitReportsReaderBuilder {
+	b.JUnitReportsReader.quarantine = append(b.JUnitReportsReader.quarantine, entries...)
+	return b
+}
+
 func (b *JUnitReportsReaderBuilder) Build() *JUnitReportsReader {
 	return &b.JUnitReportsReader
 }
 
-func parseSurefireReports(surefireReportFiles []string) ([]surefireTestsuite, error) {
-	testsuites := make([]surefireTestsuite, 0)
-	errors := make([]error, 0)
+// parseReportFiles parses the reports of the given files
+func (b *JUnitReportsReader) parseReportFiles(surefireReportFiles []string) (surefireReports, error) {
+	return b.parseReports(context.Background(), osReportFiles(surefireReportFiles))
+}
+
+// parseReports reads the files with a bounded amount of workers. Suites and summaries are returned
+// in the order of the files, regardless of the order in which they were read
+func (b *JUnitReportsReader) parseReports(ctx context.Context, files []reportFile) (surefireReports, error) {
+	reports := make([]surefireReports, len(files))
+	fileErrors := make([]*ParseError, len(files))
+	indices := make(chan int)
 	var wg sync.WaitGroup
-	reportMutex := sync.Mutex{}
-	errorMutex := sync.Mutex{}
-	wg.Add(len(surefireReportFiles))
-
-	for _, file := range surefireReportFiles {
-		go func(file string) {
-			xmlFile, openFileError := os.Open(file)
-			suite, readReportError := readReport(xmlFile)
-			suite.Filename = file
-			closeFileError := xmlFile.Close()
-			if openFileError != nil || readReportError != nil || closeFileError != nil {
-				errorMutex.Lock()
-				if openFileError != nil {
-					errors = append(errors, openFileError)
-				}
-				if readReportError != nil {
-					errors = append(errors, readReportError)
-				}
-				if closeFileError != nil {
-					errors = append(errors, closeFileError)
-				}
 
-				wg.Done()
-				errorMutex.Unlock()
-				return
-			}
-			reportMutex.Lock()
-			if suite.Name != "" {
-				testsuites = append(testsuites, suite)
+	workers := min(b.workers(), len(files))
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				if ctx.Err() != nil {
+					continue
+				}
+				reports[i], fileErrors[i] = b.readReportFile(ctx, files[i])
 			}
-			wg.Done()
-			reportMutex.Unlock()
-		}(file)
+		}()
 	}
+	for i := range files {
+		indices <- i
+	}
+	close(indices)
 	wg.Wait()
-	if len(errors) > 0 {
-		slog.Error("error reading test report files", "errors", errors)
-		return nil, fmt.Errorf("one or more error occured when reading test report files %s", errors)
+
+	if err := ctx.Err(); err != nil {
+		return surefireReports{}, err
 	}
-	return testsuites, nil
+
+	return b.joinReports(reports, fileErrors)
 }
 
-// readReport parses xml content from given reader and returns a Testsuite
-func readReport(reader io.Reader) (surefireTestsuite, error) {
-	var testsuite surefireTestsuite
+// joinReports joins the reports read from several files, in order. Files which could not be read fail
+// joining, unless in lenient mode where they are recorded as skipped
+func (b *JUnitReportsReader) joinReports(reports []surefireReports, fileErrors []*ParseError) (surefireReports, error) {
+	result := surefireReports{Testsuites: make([]surefireTestsuite, 0), Summaries: make([]surefireFailsafeSummary, 0)}
+	readErrors := make([]error, 0)
+	for _, err := range fileErrors {
+		if err != nil {
+			readErrors = append(readErrors, err)
+			result.Skipped = append(result.Skipped, err)
+		}
+	}
+	if len(readErrors) > 0 && !b.lenient {
+		return surefireReports{}, errors.Join(readErrors...)
+	}
 
-	decoder := xml.NewDecoder(reader)
-	err := decoder.Decode(&testsuite)
+	for _, report := range reports {
+		result.Testsuites = append(result.Testsuites, report.Testsuites...)
+		result.Summaries = append(result.Summaries, report.Summaries...)
+	}
+	return result, nil
+}
+
+// workers is the amount of files read concurrently
+func (b *JUnitReportsReader) workers() int {
+	if b.concurrency > 0 {
+		return b.concurrency
+	}
+
+	return runtime.NumCPU()
+}
+
+// readReportFile reads a single report file. Suites without a name are dropped, the others are assigned
+// the name and module of the file and, if enabled, the content of their output files
+func (b *JUnitReportsReader) readReportFile(ctx context.Context, file reportFile) (surefireReports, *ParseError) {
+	reader, err := file.open()
+	if err != nil {
+		return surefireReports{}, &ParseError{File: file.name, Err: err}
+	}
+	report, readReportError := b.readReport(contextReader{ctx: ctx, reader: reader})
+	closeFileError := reader.Close()
+	if readReportError != nil {
+		readReportError.File = file.name
+		return surefireReports{}, readReportError
+	}
+	if closeFileError != nil {
+		return surefireReports{}, &ParseError{File: file.name, Err: closeFileError}
+	}
+
+	testsuites := make([]surefireTestsuite, 0, len(report.Testsuites))
+	for _, suite := range report.Testsuites {
+		if suite.Name == "" {
+			continue
+		}
+		if anomalies := suiteAnomalies(suite); b.strictCounters && len(anomalies) > 0 {
+			return surefireReports{}, &ParseError{File: file.name, Err: &AnomalyError{Suite: suite.Name, Anomalies: anomalies}}
+		}
+		suite.Filename = file.name
+		suite.Module = file.module
+		if b.outputFiles && file.openSibling != nil {
+			output, err := b.readOutputFile(file, suite.Name+"-output.txt")
+			if err != nil {
+				return surefireReports{}, &ParseError{File: file.name, Err: err}
+			}
+			suite.SystemOut += output
+		}
+		testsuites = append(testsuites, suite)
+	}
+	for i := range report.Summaries {
+		report.Summaries[i].Filename = file.name
+	}
+
+	return surefireReports{Testsuites: testsuites, Summaries: report.Summaries}, nil
+}
+
+// contextReader stops reading once its context is done
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return r.reader.Read(p)
+}
+
+// readOutputFile reads an output file next to the report, which might not exist. When the output size is limited,
+// no more than required to detect truncation is read
+func (b *JUnitReportsReader) readOutputFile(file reportFile, name string) (string, error) {
+	output, err := file.openSibling(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
 	if err != nil {
-		return surefireTestsuite{}, fmt.Errorf("error decoding XML: %s", err)
+		return "", err
+	}
+	defer output.Close()
 
+	var reader io.Reader = output
+	if b.maxOutputSize > 0 {
+		reader = io.LimitReader(output, int64(b.maxOutputSize)+1)
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
 	}
 
-	return testsuite, nil
+	return string(content), nil
 }
@@ -13,16 +13,277 @@ governing permissions and limitations under the License.
 package surefire
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	a "github.com/stretchr/testify/assert"
 )
 
 func TestUnMarshalInvalidXml(t *testing.T) {
 	assert := a.New(t)
-	suites, err := parseSurefireReports([]string{
+	reports, err := NewJUnitReportsReaderBuilder().Build().parseReportFiles([]string{
 		"./sample/failsafe-summary.xml",
 		"./sample/TEST-org.example.AnotherIT.xml"})
 	assert.Nil(err)
-	assert.Equal(1, len(suites))
+	assert.Equal(1, len(reports.Testsuites))
+}
+
+func TestUnMarshalTestsuitesRoot(t *testing.T) {
+	assert := a.New(t)
+	reports, err := NewJUnitReportsReaderBuilder().Build().parseReportFiles([]string{"./sample/testsuites-aggregate.xml"})
+	assert.Nil(err)
+	assert.Equal(3, len(reports.Testsuites))
+
+	testResults := NewJUnitReportsReaderBuilder().Build().FromJUnitRepresentation(reports.Testsuites)
+	assert.Equal(4, testResults.Tests())
+	assert.Equal(1, testResults.Failures())
+	assert.Equal(1, testResults.Skipped())
+
+	first := suiteByName("org.example.FirstTest", testResults.TestSuites())
+	assert.NotNil(first)
+	assert.Equal("./sample/testsuites-aggregate.xml", first.Filename())
+	assert.Equal(0.2, first.Time())
+
+	third := suiteByName("org.example.ThirdTest", testResults.TestSuites())
+	assert.NotNil(third)
+	assert.Equal("./sample/testsuites-aggregate.xml", third.Filename())
+	assert.Nil(suiteByName("org.example", testResults.TestSuites()))
+}
+
+func TestReadSuiteMetadata(t *testing.T) {
+	assert := a.New(t)
+	testResults, err := NewJUnitReportsReaderBuilder().Build().FromReportFiles([]string{
+		"./sample/testsuites-aggregate.xml",
+		"./sample/TEST-org.example.AnotherIT.xml"})
+	assert.Nil(err)
+
+	first := suiteByName("org.example.FirstTest", testResults.TestSuites())
+	assert.NotNil(first)
+	assert.Equal(time.Date(2024, 3, 1, 10, 15, 30, 0, time.UTC), first.Timestamp())
+	assert.Equal("build-agent-1", first.Hostname())
+	assert.Equal("0", first.ID())
+	assert.Equal("org.example", first.Package())
+
+	another := suiteByName("org.example.AnotherIT", testResults.TestSuites())
+	assert.NotNil(another)
+	assert.True(another.Timestamp().IsZero())
+	assert.Equal("3.0", another.Version())
+
+	window := testResults.RunWindow()
+	assert.Equal(time.Date(2024, 3, 1, 10, 15, 30, 0, time.UTC), window.Start)
+	assert.Equal(time.Date(2024, 3, 1, 10, 15, 31, 100_000_000, time.UTC), window.End)
+	assert.Equal(1100*time.Millisecond, window.WallClock)
+	assert.Equal(500*time.Millisecond, window.CPUTime)
+}
+
+func TestUnMarshalEmptyFile(t *testing.T) {
+	assert := a.New(t)
+	_, err := NewJUnitReportsReaderBuilder().Build().readReport(strings.NewReader(""))
+	assert.NotNil(err)
+}
+
+func TestReadSuiteProperties(t *testing.T) {
+	assert := a.New(t)
+	testResults, err := NewJUnitReportsReaderBuilder().
+		WithPropertyRedactor(RedactProperties("user.home", "java.class.path")).
+		WithLabeler(func(suite TestSuite) []string {
+			return []string{"jdk-" + suite.Properties()["java.specification.version"]}
+		}).
+		Build().
+		FromReportFiles([]string{"./sample/TEST-org.example.AnotherIT.xml"})
+	assert.Nil(err)
+
+	suite := suiteByName("org.example.AnotherIT", testResults.TestSuites())
+	assert.NotNil(suite)
+	assert.Equal("Mac OS X", suite.Properties()["os.name"])
+	assert.NotContains(suite.Properties(), "user.home")
+	assert.NotContains(suite.Properties(), "java.class.path")
+	assert.Contains(suite.Labels(), "jdk-11")
+}
+
+func TestReadExceptionTypes(t *testing.T) {
+	assert := a.New(t)
+	testResults, err := NewJUnitReportsReaderBuilder().Build().
+		FromReportFiles([]string{"./sample/TEST-org.example.AnotherIT.xml"})
+	assert.Nil(err)
+
+	suite := suiteByName("org.example.AnotherIT", testResults.TestSuites())
+	assert.NotNil(suite)
+
+	failure := caseByName("failure1", suite.NonSuccessfulTestCases())
+	assert.NotNil(failure)
+	assert.Equal("org.opentest4j.AssertionFailedError", failure.Issue.Type)
+	for _, r := range failure.RerunFailures {
+		assert.Equal("org.opentest4j.AssertionFailedError", r.Type)
+	}
+
+	error1 := caseByName("error1", suite.NonSuccessfulTestCases())
+	assert.NotNil(error1)
+	assert.Equal("java.lang.RuntimeException", error1.Issue.Type)
+
+	flaky := caseByName("flakyError", suite.FlakyTestCases())
+	assert.NotNil(flaky)
+	assert.Equal("java.lang.RuntimeException", flaky.FlakyErrors[0].Type)
+}
+
+func TestReadSystemOutput(t *testing.T) {
+	assert := a.New(t)
+	dir := t.TempDir()
+	report := filepath.Join(dir, "TEST-org.example.OutputTest.xml")
+	assert.Nil(os.WriteFile(report, []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="org.example.OutputTest" time="0.1" tests="1" errors="0" skipped="0" failures="0">
+  <testcase name="prints" classname="org.example.OutputTest" time="0.1">
+    <system-out><![CDATA[hello from the test]]></system-out>
+    <system-err><![CDATA[warning from the test]]></system-err>
+  </testcase>
+  <system-out><![CDATA[suite out
+]]></system-out>
+  <system-err><![CDATA[suite err]]></system-err>
+</testsuite>`), 0o600))
+	assert.Nil(os.WriteFile(filepath.Join(dir, "org.example.OutputTest-output.txt"), []byte("redirected output"), 0o600))
+
+	testResults, err := NewJUnitReportsReaderBuilder().Build().FromReportFiles([]string{report})
+	assert.Nil(err)
+	suite := suiteByName("org.example.OutputTest", testResults.TestSuites())
+	assert.Equal("suite out\n", suite.SystemOut())
+	assert.Equal("suite err", suite.SystemErr())
+	testCase := caseByName("prints", suite.TestCases())
+	assert.Equal("hello from the test", testCase.SystemOut)
+	assert.Equal("warning from the test", testCase.SystemErr)
+
+	testResults, err = NewJUnitReportsReaderBuilder().WithOutputFiles().Build().FromReportFiles([]string{report})
+	assert.Nil(err)
+	suite = suiteByName("org.example.OutputTest", testResults.TestSuites())
+	assert.Equal("suite out\nredirected output", suite.SystemOut())
+
+	testResults, err = NewJUnitReportsReaderBuilder().WithOutputFiles().WithMaxOutputSize(5).Build().FromReportFiles([]string{report})
+	assert.Nil(err)
+	suite = suiteByName("org.example.OutputTest", testResults.TestSuites())
+	assert.Equal("suite"+truncationMarker, suite.SystemOut())
+	assert.Equal("suite"+truncationMarker, suite.SystemErr())
+	testCase = caseByName("prints", suite.TestCases())
+	assert.Equal("hello"+truncationMarker, testCase.SystemOut)
+}
+
+func TestReadFailsafeSummary(t *testing.T) {
+	assert := a.New(t)
+	testResults, err := NewJUnitReportsReaderBuilder().Build().FromReportFiles([]string{
+		"./sample/failsafe-summary.xml",
+		"./sample/TEST-org.example.AnotherIT.xml",
+		"./sample/TEST-org.example.SkippingSuiteIT.xml"})
+	assert.Nil(err)
+
+	assert.Equal(1, len(testResults.FailsafeSummaries()))
+	summary := testResults.FailsafeSummaries()[0]
+	assert.Equal("./sample/failsafe-summary.xml", summary.Filename)
+	assert.Equal(1330, summary.Completed)
+	assert.Equal(0, summary.Errors)
+	assert.Equal(0, summary.Failures)
+	assert.Equal(53, summary.Skipped)
+	assert.Nil(summary.Result)
+	assert.False(summary.Timeout)
+	assert.Equal("", summary.FailureMessage)
+
+	assert.ElementsMatch([]SummaryMismatch{
+		{Filename: "./sample/failsafe-summary.xml", Counter: "completed", Summary: 1330, Reports: 7},
+		{Filename: "./sample/failsafe-summary.xml", Counter: "errors", Summary: 0, Reports: 1},
+		{Filename: "./sample/failsafe-summary.xml", Counter: "failures", Summary: 0, Reports: 1},
+		{Filename: "./sample/failsafe-summary.xml", Counter: "skipped", Summary: 53, Reports: 1},
+	}, testResults.SummaryMismatches())
+}
+
+func TestFailsafeSummaryMatchingReports(t *testing.T) {
+	assert := a.New(t)
+	dir := t.TempDir()
+	assert.Nil(os.WriteFile(filepath.Join(dir, "failsafe-summary.xml"), []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<failsafe-summary result="255" timeout="false">
+    <completed>1</completed>
+    <errors>0</errors>
+    <failures>0</failures>
+    <skipped>1</skipped>
+</failsafe-summary>`), 0o600))
+	content, err := os.ReadFile("./sample/TEST-org.example.SkippingSuiteIT.xml")
+	assert.Nil(err)
+	assert.Nil(os.WriteFile(filepath.Join(dir, "TEST-org.example.SkippingSuiteIT.xml"), content, 0o600))
+
+	testResults, err := NewJUnitReportsReaderBuilder().Build().FromReportFiles([]string{
+		filepath.Join(dir, "failsafe-summary.xml"),
+		filepath.Join(dir, "TEST-org.example.SkippingSuiteIT.xml")})
+	assert.Nil(err)
+	assert.Equal(255, *testResults.FailsafeSummaries()[0].Result)
+	assert.Empty(testResults.SummaryMismatches())
+}
+
+func TestReadReportsInFileOrder(t *testing.T) {
+	assert := a.New(t)
+	files := []string{
+		"./sample/TEST-org.example.SkippingSuiteIT.xml",
+		"./sample/testsuites-aggregate.xml",
+		"./sample/TEST-org.example.AnotherIT.xml",
+	}
+	expected := []string{
+		"org.example.SkippingSuiteIT",
+		"org.example.FirstTest",
+		"org.example.SecondTest",
+		"org.example.ThirdTest",
+		"org.example.AnotherIT",
+	}
+
+	for _, concurrency := range []int{1, 2, 16} {
+		testResults, err := NewJUnitReportsReaderBuilder().WithConcurrency(concurrency).Build().
+			FromReportFilesContext(context.Background(), files)
+		assert.Nil(err)
+		names := make([]string, 0)
+		for _, suite := range testResults.TestSuites() {
+			names = append(names, suite.Name())
+		}
+		assert.Equal(expected, names)
+	}
+}
+
+func TestReadReportsCancelled(t *testing.T) {
+	assert := a.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testResults, err := NewJUnitReportsReaderBuilder().Build().
+		FromReportFilesContext(ctx, []string{"./sample/TEST-org.example.AnotherIT.xml"})
+	assert.Nil(testResults)
+	assert.ErrorIs(err, context.Canceled)
+}
+
+func TestSuiteAnomalies(t *testing.T) {
+	assert := a.New(t)
+	testResults, err := NewJUnitReportsReaderBuilder().Build().FromReportFiles([]string{
+		"./sample/TEST-org.example.AnotherIT.xml",
+		"./sample/TEST-org.example.SkippingSuiteIT.xml"})
+	assert.Nil(err)
+
+	another := suiteByName("org.example.AnotherIT", testResults.TestSuites())
+	assert.Equal([]Anomaly{{Counter: "tests", Declared: 2, Actual: 6}}, another.Anomalies())
+	skipping := suiteByName("org.example.SkippingSuiteIT", testResults.TestSuites())
+	assert.Empty(skipping.Anomalies())
+}
+
+func TestStrictCounters(t *testing.T) {
+	assert := a.New(t)
+	_, err := NewJUnitReportsReaderBuilder().WithStrictCounters().Build().FromReportFiles([]string{
+		"./sample/TEST-org.example.AnotherIT.xml",
+		"./sample/TEST-org.example.SkippingSuiteIT.xml"})
+
+	var anomalyError *AnomalyError
+	assert.ErrorAs(err, &anomalyError)
+	assert.Equal("org.example.AnotherIT", anomalyError.Suite)
+	assert.Contains(err.Error(), "tests declared 2 but found 6")
+
+	testResults, err := NewJUnitReportsReaderBuilder().WithStrictCounters().WithLenientParsing().Build().FromReportFiles([]string{
+		"./sample/TEST-org.example.AnotherIT.xml",
+		"./sample/TEST-org.example.SkippingSuiteIT.xml"})
+	assert.Nil(err)
+	assert.Equal(1, len(testResults.TestSuites()))
+	assert.Equal("./sample/TEST-org.example.AnotherIT.xml", testResults.SkippedFiles()[0].File)
 }
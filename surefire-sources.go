@@ -0,0 +1,132 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+)
+
+// reportFile is a single report to be read, regardless of where it is stored
+type reportFile struct {
+	// name is recorded as filename of the suites read from this report
+	name string
+
+	// module the report belongs to, empty if unknown
+	module string
+
+	open func() (io.ReadCloser, error)
+
+	// openSibling opens a file in the directory of the report, nil if the report has no directory
+	openSibling func(name string) (io.ReadCloser, error)
+}
+
+// FromReaders reads reports from readers, e.g. held in memory. The keys are recorded as filenames
+func (b *JUnitReportsReader) FromReaders(readers map[string]io.Reader) (TestResults, error) {
+	names := make([]string, 0, len(readers))
+	for name := range readers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	files := make([]reportFile, len(names))
+	for i, name := range names {
+		reader := readers[name]
+		files[i] = reportFile{
+			name: name,
+			open: func() (io.ReadCloser, error) {
+				return io.NopCloser(reader), nil
+			},
+		}
+	}
+
+	return b.fromReports(context.Background(), files)
+}
+
+// FromFS reads reports from a file system, e.g. an embed.FS. Without patterns the reports are discovered
+// like FromDirectory does, otherwise the files matching any of the patterns are read like FromGlob does
+func (b *JUnitReportsReader) FromFS(fsys fs.FS, patterns ...string) (TestResults, error) {
+	var names []string
+	var err error
+	if len(patterns) == 0 {
+		names, err = b.discoverReports(fsys)
+	} else {
+		names, err = b.globAll(fsys, patterns)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]reportFile, len(names))
+	for i, name := range names {
+		files[i] = fsReportFile(fsys, name, name)
+	}
+
+	return b.fromReports(context.Background(), files)
+}
+
+// globAll returns the files of fsys matching any of the patterns, without duplicates
+func (b *JUnitReportsReader) globAll(fsys fs.FS, patterns []string) ([]string, error) {
+	names := make([]string, 0)
+	for _, pattern := range patterns {
+		matches, err := b.glob(fsys, pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			if !slices.Contains(names, match) {
+				names = append(names, match)
+			}
+		}
+	}
+	slices.Sort(names)
+
+	return names, nil
+}
+
+// fsReportFile is a report read from fsys, recorded with the given filename
+func fsReportFile(fsys fs.FS, name string, filename string) reportFile {
+	return reportFile{
+		name:   filename,
+		module: moduleOf(name),
+		open: func() (io.ReadCloser, error) {
+			return fsys.Open(name)
+		},
+		openSibling: func(sibling string) (io.ReadCloser, error) {
+			return fsys.Open(path.Join(path.Dir(name), sibling))
+		},
+	}
+}
+
+// osReportFiles are reports read from the file system of the operating system
+func osReportFiles(files []string) []reportFile {
+	reportFiles := make([]reportFile, len(files))
+	for i, file := range files {
+		reportFiles[i] = reportFile{
+			name: file,
+			open: func() (io.ReadCloser, error) {
+				return os.Open(file)
+			},
+			openSibling: func(sibling string) (io.ReadCloser, error) {
+				return os.Open(filepath.Join(filepath.Dir(file), sibling))
+			},
+		}
+	}
+
+	return reportFiles
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestFromReaders(t *testing.T) {
+	assert := a.New(t)
+	another, err := os.ReadFile("./sample/TEST-org.example.AnotherIT.xml")
+	assert.Nil(err)
+	summary, err := os.ReadFile("./sample/failsafe-summary.xml")
+	assert.Nil(err)
+
+	testResults, err := NewJUnitReportsReaderBuilder().Build().FromReaders(map[string]io.Reader{
+		"in-memory/TEST-org.example.AnotherIT.xml": bytes.NewReader(another),
+		"in-memory/failsafe-summary.xml":           bytes.NewReader(summary),
+	})
+	assert.Nil(err)
+	assert.Equal(1, len(testResults.TestSuites()))
+	assert.Equal("in-memory/TEST-org.example.AnotherIT.xml", testResults.TestSuites()[0].Filename())
+	assert.Equal(1, len(testResults.FailsafeSummaries()))
+	assert.NotEmpty(testResults.SummaryMismatches())
+}
+
+func TestFromFS(t *testing.T) {
+	assert := a.New(t)
+	fsys := sampleFS(t)
+
+	testResults, err := NewJUnitReportsReaderBuilder().WithOutputFiles().Build().FromFS(fsys)
+	assert.Nil(err)
+	assert.Equal(2, len(testResults.TestSuites()))
+	another := suiteByName("org.example.AnotherIT", testResults.TestSuites())
+	assert.Equal("services/api/target/failsafe-reports/TEST-org.example.AnotherIT.xml", another.Filename())
+	assert.Equal("services/api", another.Module())
+	assert.Equal("redirected output", another.SystemOut())
+
+	testResults, err = NewJUnitReportsReaderBuilder().Build().FromFS(fsys, "**/TEST-*.xml", "build/*.xml")
+	assert.Nil(err)
+	assert.Equal(5, len(testResults.TestSuites()))
+	assert.NotNil(suiteByName("org.example.FirstTest", testResults.TestSuites()))
+}
+
+// sampleFS is an in-memory Maven multi-module layout with sample reports
+func sampleFS(t *testing.T) fstest.MapFS {
+	fsys := fstest.MapFS{
+		"services/api/target/failsafe-reports/org.example.AnotherIT-output.txt": {Data: []byte("redirected output")},
+	}
+	for name, sample := range map[string]string{
+		"target/surefire-reports/TEST-org.example.SkippingSuiteIT.xml":        "TEST-org.example.SkippingSuiteIT.xml",
+		"services/api/target/failsafe-reports/TEST-org.example.AnotherIT.xml": "TEST-org.example.AnotherIT.xml",
+		"build/aggregate.xml": "testsuites-aggregate.xml",
+	} {
+		content, err := os.ReadFile("./sample/" + sample)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fsys[name] = &fstest.MapFile{Data: content}
+	}
+	return fsys
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"errors"
+	"io"
+	"iter"
+)
+
+// TestCaseStream emits the test cases of a single report one by one, without holding the report in memory.
+// Like a bufio.Scanner, errors are reported by Err once iterating stopped
+type TestCaseStream struct {
+	reader   *JUnitReportsReader
+	source   io.Reader
+	filename string
+	err      error
+}
+
+// StreamTestCases returns a stream over the test cases of the report read from reader, recorded with filename.
+// Combined with WithoutPayloads, stack traces and output are skipped while decoding and never materialized
+func (b *JUnitReportsReader) StreamTestCases(reader io.Reader, filename string) *TestCaseStream {
+	return &TestCaseStream{reader: b, source: reader, filename: filename}
+}
+
+// All iterates the test cases in the order of the report. The stream can only be iterated once.
+//
+// The Suite of a streamed test case carries the attributes and properties of the suite, and the counters
+// of the test cases streamed so far, but no test cases and no anomalies. Labels are assigned before its first test case is streamed
+func (s *TestCaseStream) All() iter.Seq[TestCase] {
+	return func(yield func(TestCase) bool) {
+		suites := make(map[*surefireTestsuite]*testSuite)
+		_, err := s.reader.decodeReport(s.source, func(suite *surefireTestsuite, testcase surefireTestcase) bool {
+			if suite.Name == "" {
+				return true
+			}
+			testSuite, ok := suites[suite]
+			if !ok {
+				suite.Filename = s.filename
+				testSuite = s.reader.newTestSuite(*suite)
+				// without its test cases, the declared counters of the suite cannot be validated
+				testSuite.anomalies = nil
+				if s.reader.labeler != nil {
+					testSuite.labels = s.reader.labeler(testSuite)
+				}
+				suites[suite] = testSuite
+			}
+			testSuite.incomplete = suite.Incomplete
+
+			testCase := s.reader.toTestCase(testcase, testSuite)
+			s.reader.labelTestCase(&testCase)
+
+			return yield(testCase)
+		})
+		if err != nil && !errors.Is(err, errStopped) {
+			err.File = s.filename
+			s.err = err
+		}
+	}
+}
+
+// Err returns the error which stopped the stream, nil if the report was read completely or iteration was stopped
+func (s *TestCaseStream) Err() error {
+	return s.err
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestStreamTestCases(t *testing.T) {
+	assert := a.New(t)
+	file, err := os.Open("./sample/TEST-org.example.AnotherIT.xml")
+	assert.Nil(err)
+	defer file.Close()
+
+	stream := NewJUnitReportsReaderBuilder().WithTestCaseLabeler(func(testCase TestCase) []string {
+		return []string{string(testCase.Status)}
+	}).Build().StreamTestCases(file, "TEST-org.example.AnotherIT.xml")
+	names := make([]string, 0)
+	for testCase := range stream.All() {
+		names = append(names, testCase.Name)
+		assert.Equal([]string{string(testCase.Status)}, testCase.Labels)
+		assert.Equal("org.example.AnotherIT", testCase.Suite.Name())
+		assert.Equal("TEST-org.example.AnotherIT.xml", testCase.Suite.Filename())
+		assert.Equal("Mac OS X", testCase.Suite.Properties()["os.name"])
+		if testCase.Name == "error1" {
+			assert.Equal(Error, testCase.Status)
+			assert.Contains(testCase.Issue.Detail, "java.lang.RuntimeException: error1")
+			assert.Equal(5, testCase.AmountRerunErrors)
+		}
+	}
+	assert.Nil(stream.Err())
+	assert.Equal([]string{"success", "error1", "flaky1", "flaky2", "failure1", "flakyError"}, names)
+}
+
+func TestStreamTestCasesWithoutPayloads(t *testing.T) {
+	assert := a.New(t)
+	content, err := os.ReadFile("./sample/TEST-org.example.AnotherIT.xml")
+	assert.Nil(err)
+
+	complete := make(map[string]TestCase)
+	for testCase := range NewJUnitReportsReaderBuilder().Build().
+		StreamTestCases(bytes.NewReader(content), "TEST-org.example.AnotherIT.xml").All() {
+		complete[testCase.Name] = testCase
+	}
+
+	stream := NewJUnitReportsReaderBuilder().WithoutPayloads().Build().
+		StreamTestCases(bytes.NewReader(content), "TEST-org.example.AnotherIT.xml")
+	for testCase := range stream.All() {
+		if testCase.Issue != nil {
+			assert.NotEmpty(testCase.Issue.Message)
+			assert.NotEmpty(testCase.Issue.Type)
+			assert.Empty(testCase.Issue.Detail)
+		}
+		for _, rerun := range testCase.RerunFailures {
+			assert.Empty(rerun.Stacktrace)
+		}
+		assert.Empty(testCase.SystemOut)
+
+		// only payloads are dropped, the outcome is decoded the same way
+		full := complete[testCase.Name]
+		assert.Equal(full.Status, testCase.Status)
+		assert.Equal(full.Time, testCase.Time)
+		assert.Equal(len(full.FlakyFailures), len(testCase.FlakyFailures))
+		assert.Equal(len(full.FlakyErrors), len(testCase.FlakyErrors))
+		assert.Equal(len(full.RerunFailures), len(testCase.RerunFailures))
+		for i, rerun := range testCase.FlakyFailures {
+			assert.Equal(full.FlakyFailures[i].Message, rerun.Message)
+			assert.Empty(rerun.Stacktrace)
+		}
+	}
+	assert.Nil(stream.Err())
+	assert.Equal(6, len(complete))
+}
+
+func TestStreamTestCasesStopped(t *testing.T) {
+	assert := a.New(t)
+	stream := NewJUnitReportsReaderBuilder().Build().
+		StreamTestCases(strings.NewReader(`<testsuite name="Suite"><testcase name="1"/><testcase name="2"/><testcase name="3"`), "report.xml")
+
+	count := 0
+	for range stream.All() {
+		count++
+		break
+	}
+	assert.Equal(1, count)
+	assert.Nil(stream.Err())
+}
+
+func TestStreamTestCasesError(t *testing.T) {
+	assert := a.New(t)
+	stream := NewJUnitReportsReaderBuilder().Build().
+		StreamTestCases(strings.NewReader(`<testsuite name="Suite"><testcase name="1"/><testcase name="2"`), "report.xml")
+
+	count := 0
+	for range stream.All() {
+		count++
+	}
+	assert.Equal(1, count)
+	var parseError *ParseError
+	assert.ErrorAs(stream.Err(), &parseError)
+	assert.Equal("report.xml", parseError.File)
+}
+
+func BenchmarkReadReport(b *testing.B) {
+	content := sampleReport(b)
+	reader := NewJUnitReportsReaderBuilder().Build()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		report, err := reader.readReport(bytes.NewReader(content))
+		if err != nil {
+			b.Fatal(err)
+		}
+		reader.toTestResults(report.Testsuites)
+	}
+}
+
+func BenchmarkStreamTestCases(b *testing.B) {
+	benchmarkStream(b, NewJUnitReportsReaderBuilder().Build())
+}
+
+func BenchmarkStreamTestCasesWithoutPayloads(b *testing.B) {
+	benchmarkStream(b, NewJUnitReportsReaderBuilder().WithoutPayloads().Build())
+}
+
+func benchmarkStream(b *testing.B, reader *JUnitReportsReader) {
+	content := sampleReport(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		stream := reader.StreamTestCases(bytes.NewReader(content), "TEST-org.example.AnotherIT.xml")
+		for range stream.All() {
+		}
+		if err := stream.Err(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func sampleReport(b *testing.B) []byte {
+	content, err := os.ReadFile("./sample/TEST-org.example.AnotherIT.xml")
+	if err != nil {
+		b.Fatal(err)
+	}
+	return content
+}
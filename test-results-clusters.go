@@ -0,0 +1,199 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ClusterOptions configure how stack traces are normalized into signatures
+type ClusterOptions struct {
+	// Frames of classes starting with one of these prefixes are left out, e.g. of the JDK or test frameworks
+	FrameworkPrefixes []string
+
+	// The amount of frames kept per exception, from the top. All are kept if 0
+	MaxFrames int
+}
+
+// DefaultClusterOptions leave out frames of the JDK and common test and mocking frameworks and keep 5 frames per exception
+var DefaultClusterOptions = ClusterOptions{
+	FrameworkPrefixes: []string{
+		"java.", "javax.", "jdk.", "sun.", "com.sun.", "kotlin.", "scala.",
+		"org.junit.", "junit.", "org.testng.", "org.apache.maven.surefire.", "org.opentest4j.",
+		"org.mockito.", "net.bytebuddy.", "org.springframework.", "org.gradle.",
+	},
+	MaxFrames: 5,
+}
+
+// FailureCluster groups failing test cases whose stack traces share the same signature
+type FailureCluster struct {
+	// Short hash of the signature, stable across runs
+	ID string
+
+	// The normalized stack trace
+	Signature string
+
+	// Type of the exception of the first test case
+	Type string
+
+	// Message of the first test case, representing the cluster
+	Message string
+
+	TestCases []TestCase
+}
+
+var (
+	// lambda method, e.g. lambda$method$0
+	lambdaMethod = regexp.MustCompile(`lambda\$([\w$]+?)\$\d+`)
+	// lambda class, e.g. Test$$Lambda$123/0x0000000800c4b040
+	lambdaClass = regexp.MustCompile(`\$\$Lambda(?:\$\d+)?(?:/0x[0-9a-f]+)?`)
+	// proxy classes, e.g. com.sun.proxy.$Proxy12 or jdk.proxy2.$Proxy15
+	proxyClass = regexp.MustCompile(`(?:[\w.]+\.)?\$Proxy\d+`)
+	// classes generated by CGLIB, ByteBuddy or Mockito, e.g. Service$$EnhancerBySpringCGLIB$$1a2b3c or Service$MockitoMock$123
+	generatedClass = regexp.MustCompile(`\$\$?(\w*(?:CGLIB|ByteBuddy|MockitoMock|Enhancer|FastClass)\w*)\$\$?\w+`)
+)
+
+// ClusterFailures groups the failing and erroneous test cases of results by the signature of their stack traces,
+// using DefaultClusterOptions. The largest clusters come first
+func ClusterFailures(results TestResults) []FailureCluster {
+	return DefaultClusterOptions.ClusterFailures(results)
+}
+
+// ClusterFailures groups the failing and erroneous test cases of results by the signature of their stack traces.
+// The failures of reruns are grouped as well, so flaky test cases are part of the clusters of their failures.
+// A test case joins each cluster once. Issues without a stack trace are grouped by their type and message.
+// The largest clusters come first
+func (o ClusterOptions) ClusterFailures(results TestResults) []FailureCluster {
+	clusters := make([]FailureCluster, 0)
+	indices := make(map[string]int)
+
+	for _, suite := range results.TestSuites() {
+		for _, testCase := range suite.TestCases() {
+			joined := make(map[int]bool)
+			for _, issue := range failureIssues(testCase) {
+				signature := o.Signature(issue.Detail)
+				if signature == "" {
+					signature = strings.TrimSpace(issue.Type + ": " + issue.Message)
+				}
+				i, ok := indices[signature]
+				if !ok {
+					i = len(clusters)
+					indices[signature] = i
+					hash := sha256.Sum256([]byte(signature))
+					clusters = append(clusters, FailureCluster{
+						ID:        hex.EncodeToString(hash[:6]),
+						Signature: signature,
+						Type:      issue.Type,
+						Message:   issue.Message,
+					})
+				}
+				if !joined[i] {
+					joined[i] = true
+					clusters[i].TestCases = append(clusters[i].TestCases, testCase)
+				}
+			}
+		}
+	}
+	slices.SortStableFunc(clusters, func(a, b FailureCluster) int {
+		return cmp.Compare(len(b.TestCases), len(a.TestCases))
+	})
+
+	return clusters
+}
+
+// failureIssues returns the issue of a failing test case, followed by the failures and errors of its reruns
+func failureIssues(testCase TestCase) []Issue {
+	var issues []Issue
+	if failed(testCase) && testCase.Issue != nil {
+		issues = append(issues, *testCase.Issue)
+	}
+	for _, reruns := range [][]RerunIssue{testCase.RerunFailures, testCase.RerunErrors, testCase.FlakyFailures, testCase.FlakyErrors} {
+		for _, rerun := range reruns {
+			issues = append(issues, Issue{Message: rerun.Message, Type: rerun.Type, Detail: rerun.Stacktrace})
+		}
+	}
+
+	return issues
+}
+
+// Signature normalizes a Java stack trace, so the stack traces of the same failure in different test cases are
+// equal. Messages, line numbers, lambda and generated proxy names are stripped, framework frames and frames in common
+// with the enclosing trace are left out. Empty if stacktrace cannot be parsed by ParseStackTrace
+func (o ClusterOptions) Signature(stacktrace string) string {
+	trace, err := ParseStackTrace(stacktrace)
+	if err != nil {
+		return ""
+	}
+
+	return strings.Join(o.appendSignature(nil, trace, ""), "\n")
+}
+
+// appendSignature appends the lines of the signature of trace, followed by those of its suppressed exceptions and
+// its cause, in the order they are printed
+func (o ClusterOptions) appendSignature(signature []string, trace *StackTrace, prefix string) []string {
+	signature = append(signature, prefix+normalizeClass(trace.Exception))
+	frames := 0
+	for _, frame := range trace.Frames[:len(trace.Frames)-trace.CommonFrames] {
+		if o.MaxFrames > 0 && frames >= o.MaxFrames {
+			break
+		}
+		if normalized := o.normalizeFrame(frame); normalized != "" {
+			signature = append(signature, normalized)
+			frames++
+		}
+	}
+	for _, suppressed := range trace.Suppressed {
+		signature = o.appendSignature(signature, suppressed, "Suppressed: ")
+	}
+	if trace.Cause != nil {
+		signature = o.appendSignature(signature, trace.Cause, "Caused by: ")
+	}
+
+	return signature
+}
+
+// normalizeFrame prints a frame without line number, module and generated names, empty for framework frames
+func (o ClusterOptions) normalizeFrame(frame StackFrame) string {
+	method := frame.Method
+	if frame.Class != "" {
+		method = frame.Class + "." + frame.Method
+	}
+	method = normalizeClass(method)
+	for _, prefix := range o.FrameworkPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return ""
+		}
+	}
+
+	switch {
+	case frame.Native:
+		return "at " + method + "(Native Method)"
+	case frame.File != "":
+		return "at " + method + "(" + frame.File + ")"
+	default:
+		return "at " + method + "(Unknown Source)"
+	}
+}
+
+// normalizeClass strips the generated parts of lambda, proxy and enhanced class names
+func normalizeClass(name string) string {
+	name = lambdaMethod.ReplaceAllString(name, "lambda$$$1")
+	name = lambdaClass.ReplaceAllString(name, "$$$$Lambda")
+	name = proxyClass.ReplaceAllString(name, "$$Proxy")
+	return generatedClass.ReplaceAllString(name, "$$$$$1")
+}
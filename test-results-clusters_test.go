@@ -0,0 +1,146 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"testing"
+
+	a "github.com/stretchr/testify/assert"
+)
+
+const poolStacktrace1 = `java.lang.IllegalStateException: connection refused to db-1:5432
+	at org.example.db.Pool.connect(Pool.java:42)
+	at org.example.db.Pool.lambda$acquire$3(Pool.java:17)
+	at org.example.db.Pool$$Lambda$123/0x0000000800c4b040.get(Unknown Source)
+	at com.sun.proxy.$Proxy12.acquire(Unknown Source)
+	at java.base/java.util.Optional.orElseGet(Optional.java:369)
+	at org.example.service.Repository.find(Repository.java:88)
+	at org.example.service.Service$$EnhancerBySpringCGLIB$$1a2b3c.find(<generated>)
+	at app//org.example.FirstTest.loads(FirstTest.java:21)
+	at org.junit.platform.commons.util.ReflectionUtils.invokeMethod(ReflectionUtils.java:725)
+Caused by: java.net.ConnectException: Connection refused
+	at java.base/sun.nio.ch.Net.connect0(Native Method)
+	at org.example.db.Socket.open(Socket.java:10)
+	... 12 more
+`
+
+const poolStacktrace2 = `java.lang.IllegalStateException: connection refused to db-2:5432
+	at org.example.db.Pool.connect(Pool.java:43)
+	at org.example.db.Pool.lambda$acquire$5(Pool.java:18)
+	at org.example.db.Pool$$Lambda$77/0x0000000800d1c000.get(Unknown Source)
+	at jdk.proxy2/jdk.proxy2.$Proxy15.acquire(Unknown Source)
+	at java.base/java.util.Optional.orElseGet(Optional.java:369)
+	at org.example.service.Repository.find(Repository.java:90)
+	at org.example.service.Service$$EnhancerBySpringCGLIB$$9f8e7d.save(<generated>)
+	at app//org.example.SecondTest.saves(SecondTest.java:33)
+Caused by: java.net.ConnectException: Connection refused (Connection refused)
+	at java.base/sun.nio.ch.Net.connect0(Native Method)
+	at org.example.db.Socket.open(Socket.java:11)
+	... 9 more
+`
+
+func TestFailureSignature(t *testing.T) {
+	assert := a.New(t)
+	signature := DefaultClusterOptions.Signature(poolStacktrace1)
+
+	assert.Equal(`java.lang.IllegalStateException
+at org.example.db.Pool.connect(Pool.java)
+at org.example.db.Pool.lambda$acquire(Pool.java)
+at org.example.db.Pool$$Lambda.get(Unknown Source)
+at $Proxy.acquire(Unknown Source)
+at org.example.service.Repository.find(Repository.java)
+Caused by: java.net.ConnectException
+at org.example.db.Socket.open(Socket.java)`, signature)
+	assert.Equal(signature, DefaultClusterOptions.Signature(poolStacktrace2))
+
+	allFrames := ClusterOptions{FrameworkPrefixes: DefaultClusterOptions.FrameworkPrefixes}
+	assert.NotEqual(allFrames.Signature(poolStacktrace1), allFrames.Signature(poolStacktrace2))
+	assert.Contains(allFrames.Signature(poolStacktrace1), "at org.example.service.Service$$EnhancerBySpringCGLIB.find(<generated>)")
+	assert.Empty(DefaultClusterOptions.Signature(""))
+}
+
+func TestNestedTraceSignature(t *testing.T) {
+	assert := a.New(t)
+
+	// suppressed exceptions and causes are part of the signature, frames in common with the enclosing trace are not
+	assert.Equal(`java.lang.IllegalStateException
+at org.example.db.Repository.save(Repository.java)
+at org.example.RepositoryTest.saves(RepositoryTest.java)
+Suppressed: java.io.IOException
+at org.example.db.Pool.close(Unknown Source)
+at org.example.db.Repository.save(Repository.java)
+Caused by: java.net.SocketException
+at org.example.db.Socket.close(Socket.java)
+Caused by: org.example.db.PoolException
+at org.example.db.Pool$$Lambda.get(Unknown Source)
+at org.example.db.Pool.acquire(Pool.java)
+Caused by: java.net.ConnectException`, DefaultClusterOptions.Signature(nestedStacktrace))
+	assert.Empty(DefaultClusterOptions.Signature("expected: <1> but was: <2>"))
+}
+
+func TestClusterFailures(t *testing.T) {
+	testResults := NewJUnitReportsReaderBuilder().Build().FromJUnitRepresentation([]surefireTestsuite{
+		{
+			Name: "Suite-A",
+			Testcases: []surefireTestcase{
+				{Name: "Test-1", Classname: "A", Failure: &surefireProblem{Message: "expected 1", Type: "org.opentest4j.AssertionFailedError"}},
+				{Name: "Test-2", Classname: "A", Error: &surefireProblem{
+					Message: "connection refused to db-1:5432", Type: "java.lang.IllegalStateException", Data: poolStacktrace1}},
+				{Name: "Test-3", Classname: "A"},
+			},
+		},
+		{
+			Name: "Suite-B",
+			Testcases: []surefireTestcase{
+				{Name: "Test-4", Classname: "B", Error: &surefireProblem{
+					Message: "connection refused to db-2:5432", Type: "java.lang.IllegalStateException", Data: poolStacktrace2},
+					ReRunErrors: []surefireRerun{{
+						Message: "connection refused to db-1:5432", Type: "java.lang.IllegalStateException", Stacktrace: poolStacktrace1}}},
+				{Name: "Test-5", Classname: "B", FlakyFailure: []surefireRerun{
+					{Message: "connection refused to db-2:5432", Type: "java.lang.IllegalStateException", Stacktrace: poolStacktrace2},
+					{Message: "expected 1", Type: "org.opentest4j.AssertionFailedError"}}},
+			},
+		},
+	})
+
+	assert := a.New(t)
+	clusters := ClusterFailures(testResults)
+
+	assert.Equal(2, len(clusters))
+	// the rerun of Test-4 has the same signature, flaky failures of Test-5 are clustered as well
+	assert.Equal(3, len(clusters[0].TestCases))
+	assert.Equal("A.Test-2", clusters[0].TestCases[0].Fullname)
+	assert.Equal("B.Test-4", clusters[0].TestCases[1].Fullname)
+	assert.Equal("B.Test-5", clusters[0].TestCases[2].Fullname)
+	assert.Equal(Flaky, clusters[0].TestCases[2].Status)
+	assert.Equal("connection refused to db-1:5432", clusters[0].Message)
+	assert.Equal("java.lang.IllegalStateException", clusters[0].Type)
+	assert.Equal(12, len(clusters[0].ID))
+
+	assert.Equal("org.opentest4j.AssertionFailedError: expected 1", clusters[1].Signature)
+	assert.Equal(2, len(clusters[1].TestCases))
+	assert.Equal("B.Test-5", clusters[1].TestCases[1].Fullname)
+	assert.NotEqual(clusters[0].ID, clusters[1].ID)
+}
+
+func TestClusterQuarantinedFailures(t *testing.T) {
+	assert := a.New(t)
+	testResults := NewJUnitReportsReaderBuilder().WithQuarantine(QuarantineEntry{Pattern: "A.*"}).Build().
+		FromJUnitRepresentation([]surefireTestsuite{{Name: "Suite-A", Testcases: []surefireTestcase{
+			{Name: "Test-1", Classname: "A", Error: &surefireProblem{Message: "refused", Type: "java.lang.IllegalStateException", Data: poolStacktrace1}},
+		}}})
+
+	clusters := ClusterFailures(testResults)
+	assert.Equal(1, len(clusters))
+	assert.Equal(Quarantined, clusters[0].TestCases[0].Status)
+}
@@ -12,15 +12,25 @@ governing permissions and limitations under the License.
 
 package surefire
 
-func optionalTestProblem(p *surefireProblem) *Issue {
+import (
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+const truncationMarker = "\n[output truncated]"
+
+func (b *JUnitReportsReader) optionalTestProblem(p *surefireProblem) *Issue {
 	if p == nil {
 		return nil
 	}
 
-	return &Issue{Message: p.Message, Detail: p.Data}
+	return &Issue{Message: p.Message, Type: p.Type, Detail: b.payload(p.Data)}
 }
 
-func toReRunIssues(runs []surefireRerun) []RerunIssue {
+func (b *JUnitReportsReader) toReRunIssues(runs []surefireRerun) []RerunIssue {
 	if runs == nil || len(runs) == 0 {
 		return nil
 	}
@@ -29,9 +39,10 @@ func toReRunIssues(runs []surefireRerun) []RerunIssue {
 	for i, r := range runs {
 		issues[i] = RerunIssue{
 			Message:     r.Message,
-			Stacktrace:  r.Stacktrace,
-			SystemOut:   r.SystemOut,
-			SystemError: r.SystemError,
+			Type:        r.Type,
+			Stacktrace:  b.payload(r.Stacktrace),
+			SystemOut:   b.truncateOutput(b.payload(r.SystemOut)),
+			SystemError: b.truncateOutput(b.payload(r.SystemError)),
 		}
 	}
 
@@ -45,3 +56,178 @@ func amountOf(runs []surefireRerun) int {
 
 	return len(runs)
 }
+
+func (b *JUnitReportsReader) toProperties(properties []surefireProperty) map[string]string {
+	result := make(map[string]string, len(properties))
+	for _, p := range properties {
+		value := p.Value
+		if b.propertyRedactor != nil {
+			var keep bool
+			if value, keep = b.propertyRedactor(p.Name, value); !keep {
+				continue
+			}
+		}
+		result[p.Name] = value
+	}
+
+	return result
+}
+
+// RedactProperties returns a PropertyRedactor which drops the properties with given names
+func RedactProperties(names ...string) PropertyRedactor {
+	return func(name string, value string) (string, bool) {
+		return value, !slices.Contains(names, name)
+	}
+}
+
+// timestampLayouts are the formats of suite timestamps, which usually carry no time zone
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// toTimestamp parses the timestamp of a suite. Timestamps without a time zone are taken as UTC,
+// those which cannot be parsed as zero
+func toTimestamp(timestamp string) time.Time {
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, timestamp); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// toDuration converts seconds as written to the reports into a time.Duration
+func toDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// payload returns stack traces and output, unless payloads are dropped
+func (b *JUnitReportsReader) payload(content string) string {
+	if b.dropPayloads {
+		return ""
+	}
+
+	return content
+}
+
+// truncateOutput cuts output exceeding the maximum output size at a rune boundary and marks it as truncated
+func (b *JUnitReportsReader) truncateOutput(output string) string {
+	if b.maxOutputSize <= 0 || len(output) <= b.maxOutputSize {
+		return output
+	}
+
+	cut := b.maxOutputSize
+	for cut > 0 && !utf8.RuneStart(output[cut]) {
+		cut--
+	}
+
+	return output[:cut] + truncationMarker
+}
+
+func toFailsafeSummary(s surefireFailsafeSummary) FailsafeSummary {
+	summary := FailsafeSummary{
+		Filename:       s.Filename,
+		Completed:      s.Completed,
+		Errors:         s.Errors,
+		Failures:       s.Failures,
+		Skipped:        s.Skipped,
+		Timeout:        s.Timeout,
+		FailureMessage: s.FailureMessage,
+	}
+	if result, err := strconv.Atoi(s.Result); err == nil {
+		summary.Result = &result
+	}
+
+	return summary
+}
+
+// summaryMismatches compares the counters of a summary with those aggregated from suites of the same directory.
+// Quarantined test cases are counted as the failures or errors Failsafe reported them as
+func summaryMismatches(summary FailsafeSummary, suites []TestSuite) []SummaryMismatch {
+	dir := filepath.Dir(summary.Filename)
+	var completed, errors, failures, skipped int
+	for _, suite := range suites {
+		if filepath.Dir(suite.Filename()) != dir {
+			continue
+		}
+		completed += len(suite.TestCases())
+		errors += suite.Error()
+		failures += suite.Failure()
+		skipped += suite.Skipped()
+		for _, testCase := range suite.TestCases() {
+			if testCase.Quarantine == nil {
+				continue
+			}
+			switch testCase.QuarantinedStatus {
+			case Failure:
+				failures++
+			case Error:
+				errors++
+			}
+		}
+	}
+
+	mismatches := make([]SummaryMismatch, 0)
+	for _, c := range []struct {
+		counter          string
+		summary, reports int
+	}{
+		{"completed", summary.Completed, completed},
+		{"errors", summary.Errors, errors},
+		{"failures", summary.Failures, failures},
+		{"skipped", summary.Skipped, skipped},
+	} {
+		if c.summary != c.reports {
+			mismatches = append(mismatches, SummaryMismatch{
+				Filename: summary.Filename,
+				Counter:  c.counter,
+				Summary:  c.summary,
+				Reports:  c.reports,
+			})
+		}
+	}
+
+	return mismatches
+}
+
+// suiteAnomalies compares the counters declared by a suite with those computed from its test cases.
+// Counters which are not declared are not compared, nor are those of suites recovered from a truncated report,
+// which lack the test cases that were cut off
+func suiteAnomalies(suite surefireTestsuite) []Anomaly {
+	if suite.Incomplete {
+		return []Anomaly{}
+	}
+
+	var errors, failures, skipped int
+	for _, testcase := range suite.Testcases {
+		switch {
+		case testcase.Skipped != nil:
+			skipped++
+		case testcase.Failure != nil:
+			failures++
+		case testcase.Error != nil:
+			errors++
+		}
+	}
+
+	anomalies := make([]Anomaly, 0)
+	for _, c := range []struct {
+		counter  string
+		declared *int
+		actual   int
+	}{
+		{"tests", suite.Tests, len(suite.Testcases)},
+		{"errors", suite.Errors, errors},
+		{"failures", suite.Failures, failures},
+		{"skipped", suite.Skipped, skipped},
+	} {
+		if c.declared != nil && *c.declared != c.actual {
+			anomalies = append(anomalies, Anomaly{Counter: c.counter, Declared: *c.declared, Actual: c.actual})
+		}
+	}
+
+	return anomalies
+}
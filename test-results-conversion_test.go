@@ -14,7 +14,9 @@ package surefire
 
 import (
 	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	a "github.com/stretchr/testify/assert"
 )
@@ -395,3 +397,139 @@ func caseByName(casename string, cases []TestCase) *TestCase {
 	}
 	return nil
 }
+
+func TestTestCaseTime(t *testing.T) {
+	suites := []surefireTestsuite{
+		{
+			Name: "Timed-Suite",
+			Time: 1.5,
+			Testcases: []surefireTestcase{
+				{
+					Name: "Test-1",
+					Time: 1.25,
+				},
+				{
+					Name: "Test-2",
+					Time: 0.25,
+				},
+			},
+		},
+	}
+	assert := a.New(t)
+	testResult := NewJUnitReportsReaderBuilder().Build().FromJUnitRepresentation(suites)
+
+	suite := suiteByName("Timed-Suite", testResult.TestSuites())
+	assert.NotNil(suite)
+	assert.Equal(1500*time.Millisecond, suite.Duration())
+
+	testCase1 := caseByName("Test-1", suite.TestCases())
+	assert.Equal(1.25, testCase1.Time)
+	assert.Equal(1250*time.Millisecond, testCase1.Duration())
+
+	testCase2 := caseByName("Test-2", suite.TestCases())
+	assert.Equal(0.25, testCase2.Time)
+	assert.Equal(250*time.Millisecond, testCase2.Duration())
+}
+
+func TestAnomaliesOfDeclaredCounters(t *testing.T) {
+	declared := func(i int) *int { return &i }
+	suites := []surefireTestsuite{
+		{
+			Name:     "Declaring-Suite",
+			Tests:    declared(3),
+			Failures: declared(0),
+			Errors:   declared(1),
+			Testcases: []surefireTestcase{
+				{Name: "Test-1", Failure: &surefireProblem{Message: "Failure-1"}},
+				{Name: "Test-2", Error: &surefireProblem{Message: "Error-1"}},
+			},
+		},
+		{
+			Name: "Undeclared-Suite",
+			Testcases: []surefireTestcase{
+				{Name: "Test-3"},
+			},
+		},
+	}
+	assert := a.New(t)
+	testResult := NewJUnitReportsReaderBuilder().Build().FromJUnitRepresentation(suites)
+
+	assert.Equal([]Anomaly{
+		{Counter: "tests", Declared: 3, Actual: 2},
+		{Counter: "failures", Declared: 0, Actual: 1},
+	}, suiteByName("Declaring-Suite", testResult.TestSuites()).Anomalies())
+	assert.Empty(suiteByName("Undeclared-Suite", testResult.TestSuites()).Anomalies())
+}
+
+func TestSuiteTimestamps(t *testing.T) {
+	assert := a.New(t)
+	assert.Equal(time.Date(2024, 3, 1, 10, 15, 30, 0, time.UTC), toTimestamp("2024-03-01T10:15:30"))
+	assert.Equal(time.Date(2024, 3, 1, 10, 15, 30, 250_000_000, time.UTC), toTimestamp("2024-03-01T10:15:30.250"))
+	assert.Equal(time.Date(2024, 3, 1, 10, 15, 30, 0, time.UTC), toTimestamp("2024-03-01 10:15:30"))
+	assert.True(toTimestamp("2024-03-01T11:15:30+01:00").Equal(time.Date(2024, 3, 1, 10, 15, 30, 0, time.UTC)))
+	assert.True(toTimestamp("").IsZero())
+	assert.True(toTimestamp("yesterday").IsZero())
+}
+
+func TestRunWindowParallelism(t *testing.T) {
+	suites := []surefireTestsuite{
+		{Name: "Suite-1", Time: 2, Timestamp: "2024-03-01T10:00:00", Testcases: []surefireTestcase{{Name: "Test-1"}}},
+		{Name: "Suite-2", Time: 2, Timestamp: "2024-03-01T10:00:00", Testcases: []surefireTestcase{{Name: "Test-2"}}},
+		{Name: "Suite-3", Time: 5, Testcases: []surefireTestcase{{Name: "Test-3"}}},
+	}
+	assert := a.New(t)
+	window := NewJUnitReportsReaderBuilder().Build().FromJUnitRepresentation(suites).RunWindow()
+
+	assert.Equal(2*time.Second, window.WallClock)
+	assert.Equal(4*time.Second, window.CPUTime)
+	assert.Equal(2.0, window.Parallelism())
+	assert.Equal(0.0, RunWindow{}.Parallelism())
+}
+
+func TestAssignTestCaseLabels(t *testing.T) {
+	suites := []surefireTestsuite{
+		{
+			Name: "Repository-SuiteIT",
+			Testcases: []surefireTestcase{
+				{Name: "Test-db-1", Classname: "RepositoryIT", Time: 3},
+				{Name: "Test-db-2", Classname: "RepositoryIT", Error: &surefireProblem{Message: "Error-1", Type: "java.sql.SQLException"}},
+				{Name: "Test-3", Classname: "RepositoryIT", FlakyFailure: []surefireRerun{{Message: "Flaky-Failure-1"}}},
+			},
+		},
+		{
+			Name: "Unit-Suite",
+			Testcases: []surefireTestcase{
+				{Name: "Test-4", Classname: "Unit", Time: 2},
+				{Name: "Test-5", Classname: "Unit", Skipped: &surefireSkipped{Message: "Skipped-1"}},
+			},
+		},
+	}
+	labeler := func(testCase TestCase) []string {
+		var labels []string
+		if strings.HasSuffix(testCase.Suite.Name(), "IT") {
+			labels = append(labels, "integration")
+		}
+		if strings.Contains(testCase.Name, "-db-") || testCase.Issue != nil && strings.HasPrefix(testCase.Issue.Type, "java.sql.") {
+			labels = append(labels, "db", "db")
+		}
+		if testCase.Duration() > time.Second {
+			labels = append(labels, "slow")
+		}
+		return labels
+	}
+
+	assert := a.New(t)
+	testResult := NewJUnitReportsReaderBuilder().WithTestCaseLabeler(labeler).Build().FromJUnitRepresentation(suites)
+
+	suite := suiteByName("Repository-SuiteIT", testResult.TestSuites())
+	assert.Equal([]string{"integration", "db", "slow"}, caseByName("Test-db-1", suite.TestCases()).Labels)
+	assert.Empty(caseByName("Test-5", suiteByName("Unit-Suite", testResult.TestSuites()).TestCases()).Labels)
+
+	assert.Equal(LabelCounts{Tests: 3, Successes: 2, Errors: 1, Flakes: 1}, testResult.Counts("integration"))
+	assert.Equal(LabelCounts{Tests: 2, Successes: 1, Errors: 1}, testResult.Counts("db"))
+	assert.Equal(LabelCounts{Tests: 2, Successes: 2}, testResult.Counts("slow"))
+	assert.Equal(LabelCounts{}, testResult.Counts("unknown"))
+
+	// counters per label are recomputed when merging
+	assert.Equal(LabelCounts{Tests: 2, Successes: 2}, Merge(testResult).Counts("slow"))
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import "time"
+
+// Change classifies how a test case changed from one run to another
+type Change string
+
+const (
+	NewlyFailing Change = "newly-failing"
+	NewlyPassing Change = "newly-passing"
+	StillFailing Change = "still-failing"
+	NewlyFlaky   Change = "newly-flaky"
+	NewlySkipped Change = "newly-skipped"
+	Added        Change = "added"
+	Removed      Change = "removed"
+	Slower       Change = "slower"
+)
+
+// DiffOptions configure when a test case counts as significantly slower. Fields which are zero or negative take
+// the value of DefaultDiffOptions
+type DiffOptions struct {
+	// How many times longer a test case needs to run than before
+	SlowdownFactor float64
+
+	// How much longer a test case needs to run than before at least, so short tests do not count because of noise
+	MinSlowdown time.Duration
+}
+
+// DefaultDiffOptions consider test cases significantly slower which run at least twice and a second longer than before
+var DefaultDiffOptions = DiffOptions{SlowdownFactor: 2, MinSlowdown: time.Second}
+
+// TestCaseDiff is the change of a single test case
+type TestCaseDiff struct {
+	// Full qualified name of the test case
+	Fullname string
+
+	// Name of the suite the test case belongs to
+	Suite string
+
+	Change Change
+
+	// The test case in the base run, nil if it was added
+	Base *TestCase
+
+	// The test case in the head run, nil if it was removed
+	Head *TestCase
+}
+
+// SuiteDiff rolls up the changes of the test cases of a suite
+type SuiteDiff struct {
+	// Name of the suite
+	Name string
+
+	// The amount of test cases per change
+	Counts map[Change]int
+
+	TestCases []TestCaseDiff
+}
+
+// RunDiff lists the test cases which changed from a base run to a head run. Unchanged test cases are left out
+type RunDiff struct {
+	TestCases []TestCaseDiff
+
+	Suites []SuiteDiff
+}
+
+// Changed returns the test cases with given change
+func (d RunDiff) Changed(change Change) []TestCaseDiff {
+	changed := make([]TestCaseDiff, 0)
+	for _, testCase := range d.TestCases {
+		if testCase.Change == change {
+			changed = append(changed, testCase)
+		}
+	}
+
+	return changed
+}
+
+// Diff compares the head run to the base run, e.g. of a pull request to its base branch, using DefaultDiffOptions
+func Diff(base, head TestResults) RunDiff {
+	return DefaultDiffOptions.Diff(base, head)
+}
+
+// Diff compares the head run to the base run. Test cases are joined by Fullname, each one is classified by
+// the first change that applies: still failing, newly failing, newly skipped, newly passing, newly flaky or slower
+func (o DiffOptions) Diff(base, head TestResults) RunDiff {
+	baseCases, baseOrder := testCasesByFullname(base)
+	headCases, headOrder := testCasesByFullname(head)
+
+	changed := make([]TestCaseDiff, 0)
+	for _, fullname := range headOrder {
+		headCase := headCases[fullname]
+		baseCase, ok := baseCases[fullname]
+		if !ok {
+			changed = append(changed, TestCaseDiff{Fullname: fullname, Suite: headCase.Suite.Name(), Change: Added, Head: &headCase})
+			continue
+		}
+		if change, ok := o.classify(baseCase, headCase); ok {
+			changed = append(changed, TestCaseDiff{Fullname: fullname, Suite: headCase.Suite.Name(), Change: change, Base: &baseCase, Head: &headCase})
+		}
+	}
+	for _, fullname := range baseOrder {
+		if _, ok := headCases[fullname]; !ok {
+			baseCase := baseCases[fullname]
+			changed = append(changed, TestCaseDiff{Fullname: fullname, Suite: baseCase.Suite.Name(), Change: Removed, Base: &baseCase})
+		}
+	}
+
+	return RunDiff{TestCases: changed, Suites: rollup(changed)}
+}
+
+// classify tells how a test case which ran in both runs changed, if at all
+func (o DiffOptions) classify(base, head TestCase) (Change, bool) {
+	switch {
+	case failed(base) && failed(head):
+		return StillFailing, true
+	case failed(head):
+		return NewlyFailing, true
+	case head.Status == Skip && base.Status != Skip:
+		return NewlySkipped, true
+	case failed(base) && head.Status != Skip:
+		return NewlyPassing, true
+	case head.Status == Flaky && base.Status != Flaky:
+		return NewlyFlaky, true
+	case head.Status != Skip && base.Status != Skip && o.slower(base.Duration(), head.Duration()):
+		return Slower, true
+	default:
+		return "", false
+	}
+}
+
+// slower tells whether head is significantly slower than base. Test cases without a recorded base time
+// are never slower, as there is nothing to compare with
+func (o DiffOptions) slower(base, head time.Duration) bool {
+	if o.SlowdownFactor <= 0 {
+		o.SlowdownFactor = DefaultDiffOptions.SlowdownFactor
+	}
+	if o.MinSlowdown <= 0 {
+		o.MinSlowdown = DefaultDiffOptions.MinSlowdown
+	}
+
+	return base > 0 && float64(head) >= float64(base)*o.SlowdownFactor && head-base >= o.MinSlowdown
+}
+
+// failed tells whether a test case failed or was in error, regardless of whether it was quarantined
+func failed(testCase TestCase) bool {
+	outcome := testCase.Outcome()
+	return outcome == Failure || outcome == Error
+}
+
+// rollup groups the changed test cases by suite, in the order the suites appear in
+func rollup(testCases []TestCaseDiff) []SuiteDiff {
+	suites := make([]SuiteDiff, 0)
+	indices := make(map[string]int)
+	for _, testCase := range testCases {
+		i, ok := indices[testCase.Suite]
+		if !ok {
+			i = len(suites)
+			indices[testCase.Suite] = i
+			suites = append(suites, SuiteDiff{Name: testCase.Suite, Counts: make(map[Change]int)})
+		}
+		suites[i].Counts[testCase.Change]++
+		suites[i].TestCases = append(suites[i].TestCases, testCase)
+	}
+
+	return suites
+}
+
+// testCasesByFullname indexes the test cases of results by Fullname, keeping the first of the same name,
+// along with the order they were read in
+func testCasesByFullname(results TestResults) (map[string]TestCase, []string) {
+	testCases := make(map[string]TestCase)
+	var order []string
+	for _, suite := range results.TestSuites() {
+		for _, testCase := range suite.TestCases() {
+			if _, ok := testCases[testCase.Fullname]; !ok {
+				testCases[testCase.Fullname] = testCase
+				order = append(order, testCase.Fullname)
+			}
+		}
+	}
+
+	return testCases, order
+}
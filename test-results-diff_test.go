@@ -0,0 +1,150 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"testing"
+	"time"
+
+	a "github.com/stretchr/testify/assert"
+)
+
+func changes(diff RunDiff) map[string]Change {
+	result := make(map[string]Change)
+	for _, testCase := range diff.TestCases {
+		result[testCase.Fullname] = testCase.Change
+	}
+	return result
+}
+
+func TestDiffRuns(t *testing.T) {
+	reader := NewJUnitReportsReaderBuilder().Build()
+	base := reader.FromJUnitRepresentation([]surefireTestsuite{
+		{
+			Name: "Suite-A",
+			Testcases: []surefireTestcase{
+				{Name: "Unchanged", Classname: "A", Time: 1},
+				{Name: "Breaking", Classname: "A"},
+				{Name: "Fixed", Classname: "A", Failure: &surefireProblem{Message: "Failure-1"}},
+				{Name: "Broken", Classname: "A", Error: &surefireProblem{Message: "Error-1"}},
+				{Name: "Flaking", Classname: "A"},
+				{Name: "Disabled", Classname: "A"},
+				{Name: "Slowing", Classname: "A", Time: 1},
+			},
+		},
+		{
+			Name: "Suite-B",
+			Testcases: []surefireTestcase{
+				{Name: "Deleted", Classname: "B"},
+			},
+		},
+	})
+	head := reader.FromJUnitRepresentation([]surefireTestsuite{
+		{
+			Name: "Suite-A",
+			Testcases: []surefireTestcase{
+				{Name: "Unchanged", Classname: "A", Time: 1.5},
+				{Name: "Breaking", Classname: "A", Failure: &surefireProblem{Message: "Failure-2"}},
+				{Name: "Fixed", Classname: "A"},
+				{Name: "Broken", Classname: "A", Failure: &surefireProblem{Message: "Failure-3"}},
+				{Name: "Flaking", Classname: "A", FlakyFailure: []surefireRerun{{Message: "Flaky-Failure-1"}}},
+				{Name: "Disabled", Classname: "A", Skipped: &surefireSkipped{Message: "Skipped-1"}},
+				{Name: "Slowing", Classname: "A", Time: 3},
+				{Name: "New", Classname: "A"},
+			},
+		},
+	})
+
+	assert := a.New(t)
+	diff := Diff(base, head)
+
+	assert.Equal(map[string]Change{
+		"A.Breaking": NewlyFailing,
+		"A.Fixed":    NewlyPassing,
+		"A.Broken":   StillFailing,
+		"A.Flaking":  NewlyFlaky,
+		"A.Disabled": NewlySkipped,
+		"A.Slowing":  Slower,
+		"A.New":      Added,
+		"B.Deleted":  Removed,
+	}, changes(diff))
+
+	removed := diff.Changed(Removed)
+	assert.Equal(1, len(removed))
+	assert.Nil(removed[0].Head)
+	assert.Equal("Suite-B", removed[0].Suite)
+	added := diff.Changed(Added)
+	assert.Equal(1, len(added))
+	assert.Nil(added[0].Base)
+	assert.Equal("A.New", added[0].Head.Fullname)
+
+	assert.Equal(2, len(diff.Suites))
+	assert.Equal("Suite-A", diff.Suites[0].Name)
+	assert.Equal(7, len(diff.Suites[0].TestCases))
+	assert.Equal(1, diff.Suites[0].Counts[NewlyFailing])
+	assert.Equal(map[Change]int{Removed: 1}, diff.Suites[1].Counts)
+}
+
+func TestDiffQuarantinedRun(t *testing.T) {
+	suites := func(failure *surefireProblem) []surefireTestsuite {
+		return []surefireTestsuite{{Name: "Suite-A", Testcases: []surefireTestcase{
+			{Name: "Known", Classname: "A", Failure: &surefireProblem{Message: "Failure-1"}},
+			{Name: "Breaking", Classname: "A", Error: failure},
+		}}}
+	}
+	quarantine := []QuarantineEntry{{Pattern: "A.*"}}
+	base := NewJUnitReportsReaderBuilder().Build().FromJUnitRepresentation(suites(nil))
+	head := NewJUnitReportsReaderBuilder().WithQuarantine(quarantine...).Build().
+		FromJUnitRepresentation(suites(&surefireProblem{Message: "Error-1"}))
+
+	assert := a.New(t)
+	assert.Equal(2, head.Quarantined())
+	assert.Equal(map[string]Change{
+		"A.Known":    StillFailing,
+		"A.Breaking": NewlyFailing,
+	}, changes(Diff(base, head)))
+
+	// quarantined tests passing in head are fixed
+	fixed := NewJUnitReportsReaderBuilder().Build().FromJUnitRepresentation([]surefireTestsuite{{Name: "Suite-A", Testcases: []surefireTestcase{
+		{Name: "Known", Classname: "A"},
+		{Name: "Breaking", Classname: "A"},
+	}}})
+	assert.Equal(map[string]Change{
+		"A.Known":    NewlyPassing,
+		"A.Breaking": NewlyPassing,
+	}, changes(Diff(head, fixed)))
+}
+
+func TestDiffSlowdownThresholds(t *testing.T) {
+	assert := a.New(t)
+	options := DiffOptions{SlowdownFactor: 1.5, MinSlowdown: 100 * time.Millisecond}
+	run := func(seconds float64) TestResults {
+		return NewJUnitReportsReaderBuilder().Build().FromJUnitRepresentation([]surefireTestsuite{
+			{Name: "Suite-A", Testcases: []surefireTestcase{{Name: "Test-1", Classname: "A", Time: seconds}}},
+		})
+	}
+
+	assert.Empty(Diff(run(0.1), run(0.3)).TestCases)
+	assert.Equal(Slower, options.Diff(run(0.1), run(0.3)).TestCases[0].Change)
+	assert.Empty(options.Diff(run(0.01), run(0.05)).TestCases)
+	assert.Empty(options.Diff(run(1), run(1.4)).TestCases)
+
+	// unset options take the defaults
+	assert.Empty(DiffOptions{}.Diff(run(1), run(1.5)).TestCases)
+	assert.Equal(Slower, DiffOptions{}.Diff(run(1), run(2.5)).TestCases[0].Change)
+	assert.Empty(DiffOptions{SlowdownFactor: 1.5}.Diff(run(0.1), run(0.3)).TestCases)
+	assert.Equal(Slower, DiffOptions{MinSlowdown: 100 * time.Millisecond}.Diff(run(0.1), run(0.3)).TestCases[0].Change)
+
+	// without a base time there is nothing to compare with
+	assert.Empty(Diff(run(0), run(5)).TestCases)
+}
@@ -12,6 +12,12 @@ governing permissions and limitations under the License.
 
 package surefire
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 // TestResults aggregates all TestSuites being read from the surefire reports and expose statistics
 type TestResults interface {
 	// All being read from the surefire reports. Except for those with an empty name attribute
@@ -34,17 +40,45 @@ type TestResults interface {
 
 	// The amount flaky tests
 	Flakes() int
+
+	// The amount of quarantined tests, which failed or were in error
+	Quarantined() int
+
+	// Quarantine entries which expired and no longer quarantine tests
+	ExpiredQuarantine() []QuarantineEntry
+
+	// Counters of the test cases with given label, assigned by a TestCaseLabeler
+	Counts(label string) LabelCounts
+
+	// Summaries read from failsafe-summary.xml files
+	FailsafeSummaries() []FailsafeSummary
+
+	// Counters of failsafe summaries which disagree with the suites read from the same directory
+	SummaryMismatches() []SummaryMismatch
+
+	// Report files which could not be read in lenient mode, along with the reason
+	SkippedFiles() []*ParseError
+
+	// The time window the suites with a timestamp ran in
+	RunWindow() RunWindow
 }
 
 // Implementation of TestResults
 type testResults struct {
-	tests     int
-	successes int
-	failures  int
-	errors    int
-	skipped   int
-	flakes    int
-	suites    []TestSuite
+	tests      int
+	successes  int
+	failures   int
+	errors     int
+	skipped    int
+	flakes     int
+	suites     []TestSuite
+	summaries  []FailsafeSummary
+	mismatches []SummaryMismatch
+
+	skippedFiles []*ParseError
+	quarantined  int
+	expired      []QuarantineEntry
+	labelCounts  map[string]LabelCounts
 }
 
 // TestSuite represents a set of TestCase and exposes statistics
@@ -76,17 +110,56 @@ type TestSuite interface {
 	// The amount of skipped tests in this suite
 	Skipped() int
 
+	// The amount of quarantined tests in this suite
+	Quarantined() int
+
 	// Name of the suite
 	Name() string
 
 	// Filename from which the result was coming from
 	Filename() string
 
+	// Module the suite belongs to, relative to the directory reports were discovered in. Empty if unknown
+	Module() string
+
 	// The time this suite needs to run
 	Time() float64
 
+	// The time this suite needs to run as a time.Duration
+	Duration() time.Duration
+
 	// Labels the suite is assigned to
 	Labels() []string
+
+	// System properties of the JVM which ran this suite, after redaction
+	Properties() map[string]string
+
+	// Output written to stdout by this suite, outside of test cases
+	SystemOut() string
+
+	// Output written to stderr by this suite, outside of test cases
+	SystemErr() string
+
+	// Whether the suite was recovered from a truncated report, and thus misses test cases
+	Incomplete() bool
+
+	// Counters declared by the report which disagree with the test cases read
+	Anomalies() []Anomaly
+
+	// When the suite started, zero if the report has no timestamp
+	Timestamp() time.Time
+
+	// Host the suite ran on
+	Hostname() string
+
+	// Id of the suite within its report
+	ID() string
+
+	// Package of the suite
+	Package() string
+
+	// Version of the report format
+	Version() string
 }
 
 // implementation of TestSuite
@@ -94,14 +167,28 @@ type testSuite struct {
 	testcases []TestCase
 	name      string
 	filename  string
+	module    string
 	time      float64
 	successes int
 	failures  int
 	errors    int
 	skipped   int
 
+	quarantined int
+
 	// Labels the suite is assigned to
 	labels []string
+
+	properties map[string]string
+	systemOut  string
+	systemErr  string
+	incomplete bool
+	anomalies  []Anomaly
+	timestamp  time.Time
+	hostname   string
+	id         string
+	pkg        string
+	version    string
 }
 
 // TestCase represents a single test run
@@ -115,7 +202,7 @@ type TestCase struct {
 	// Status of this test case
 	Status Status
 
-	// The time this test case needs to run
+	// The time in seconds this test case needs to run
 	Time float64
 
 	// Full qualified name of the test case
@@ -153,12 +240,47 @@ type TestCase struct {
 
 	// Set for a skipped test, nil otherwise
 	Skipped *Skipped
+
+	// Output written to stdout by this test case
+	SystemOut string
+
+	// Output written to stderr by this test case
+	SystemErr string
+
+	// Outcome of this test case in each attempt of a retried run, set by ReconcileAttempts
+	Attempts []Attempt
+
+	// The entry which quarantined this test case, nil if it is not quarantined
+	Quarantine *QuarantineEntry
+
+	// The status this test case had before it was quarantined, Failure or Error. Empty if it is not quarantined
+	QuarantinedStatus Status
+
+	// Labels the test case is assigned to by a TestCaseLabeler
+	Labels []string
+}
+
+// Attempt is the outcome of a test case in one attempt of a retried run
+type Attempt struct {
+	// Index of the attempt in the attempts given to ReconcileAttempts, starting at 0
+	Attempt int
+
+	// Status of the test case in this attempt
+	Status Status
+
+	// The time in seconds the test case needed to run in this attempt
+	Time float64
+
+	// Issue the test case had in this attempt, nil if there was none
+	Issue *Issue
 }
 
 // Issue encapsulates a failure or error
 type Issue struct {
 	// Message for that issue
 	Message string
+	// Type of the exception which caused that issue, e.g. org.opentest4j.AssertionFailedError
+	Type string
 	// Details for that issue
 	Detail string
 }
@@ -167,6 +289,8 @@ type Issue struct {
 type RerunIssue struct {
 	// Message for that RerunIssue
 	Message string
+	// Type of the exception which caused that RerunIssue
+	Type string
 	// Stacktrace for that RerunIssue
 	Stacktrace string
 	// SystemOut for that RerunIssue
@@ -178,10 +302,148 @@ type RerunIssue struct {
 // Issue encapsulates the message of a skipped test
 type Skipped struct {
 	Message string
+	// Type given for the skip, if any
+	Type string
+}
+
+// FailsafeSummary is the summary of a Failsafe run, as written to failsafe-summary.xml
+type FailsafeSummary struct {
+	// File the summary was read from
+	Filename string
+
+	// The amount of tests that were run, including skipped ones
+	Completed int
+
+	// The amount of tests in error
+	Errors int
+
+	// The amount of failing tests
+	Failures int
+
+	// The amount of skipped tests
+	Skipped int
+
+	// Result code of the run, nil if none was written
+	Result *int
+
+	// Whether the run timed out
+	Timeout bool
+
+	// Message describing why the run failed, if any
+	FailureMessage string
+}
+
+// SummaryMismatch reports a counter of a FailsafeSummary which disagrees with the suites read from the same directory,
+// e.g. because a forked JVM crashed before writing its report
+type SummaryMismatch struct {
+	// File the summary was read from
+	Filename string
+
+	// Name of the counter, one of completed, errors, failures or skipped
+	Counter string
+
+	// Value of the counter in the summary
+	Summary int
+
+	// Value of the counter aggregated from the suites
+	Reports int
+}
+
+// Anomaly is a counter declared by a suite's report which disagrees with the test cases of that suite,
+// e.g. because the report was corrupted or merged by hand
+type Anomaly struct {
+	// Name of the counter, one of tests, errors, failures or skipped
+	Counter string
+
+	// Value of the counter declared by the report
+	Declared int
+
+	// Value of the counter computed from the test cases
+	Actual int
+}
+
+// AnomalyError is reported in strict mode for a suite with anomalies
+type AnomalyError struct {
+	// Name of the suite
+	Suite string
+
+	Anomalies []Anomaly
+}
+
+func (e *AnomalyError) Error() string {
+	counters := make([]string, len(e.Anomalies))
+	for i, a := range e.Anomalies {
+		counters[i] = fmt.Sprintf("%s declared %d but found %d", a.Counter, a.Declared, a.Actual)
+	}
+
+	return fmt.Sprintf("suite %s has anomalies: %s", e.Suite, strings.Join(counters, ", "))
+}
+
+// RunWindow is the time window suites ran in, derived from their timestamps and durations
+type RunWindow struct {
+	// When the first suite started
+	Start time.Time
+
+	// When the last suite ended
+	End time.Time
+
+	// Time between start and end
+	WallClock time.Duration
+
+	// Summed up durations of the suites
+	CPUTime time.Duration
+}
+
+// Parallelism is the ratio of CPU time to wall clock time, e.g. 4 if on average four suites ran in parallel
+func (w RunWindow) Parallelism() float64 {
+	if w.WallClock <= 0 {
+		return 0
+	}
+
+	return float64(w.CPUTime) / float64(w.WallClock)
 }
 
 type Labeler func(TestSuite) []string
 
+// TestCaseLabeler assigns labels to a test case, e.g. by its name, status, type of issue or duration
+type TestCaseLabeler func(TestCase) []string
+
+// LabelCounts are the counters of the test cases with a label
+type LabelCounts struct {
+	Tests       int
+	Successes   int
+	Failures    int
+	Errors      int
+	Skipped     int
+	Flakes      int
+	Quarantined int
+}
+
+// count updates the counters by a test case of given status. Flaky test cases count as successful
+func (c LabelCounts) count(status Status) LabelCounts {
+	c.Tests++
+	switch status {
+	case Skip:
+		c.Skipped++
+	case Failure:
+		c.Failures++
+	case Error:
+		c.Errors++
+	case Quarantined:
+		c.Quarantined++
+	case Flaky:
+		c.Flakes++
+		c.Successes++
+	default:
+		c.Successes++
+	}
+
+	return c
+}
+
+// PropertyRedactor is called for every suite property. It returns the value to keep, or false to drop the property
+type PropertyRedactor func(name string, value string) (string, bool)
+
 // Status represents the status of a test case
 type Status string
 
@@ -191,12 +453,30 @@ const (
 	Failure Status = "failure"
 	Error   Status = "error"
 	Flaky   Status = "flaky"
+
+	// Quarantined is the status of a failing or erroneous test case matching a quarantine entry
+	Quarantined Status = "quarantined"
 )
 
 func (r *testResults) TestSuites() []TestSuite {
 	return r.suites
 }
 
+// Duration returns the time this test case needs to run as a time.Duration
+func (t TestCase) Duration() time.Duration {
+	return toDuration(t.Time)
+}
+
+// Outcome returns the status of this test case, where quarantined test cases have the status they had
+// before they were quarantined
+func (t TestCase) Outcome() Status {
+	if t.Status == Quarantined {
+		return t.QuarantinedStatus
+	}
+
+	return t.Status
+}
+
 func (t *testSuite) NonSuccessfulTestCases() []TestCase {
 	return t.filterTestCases(func(testCase TestCase) bool {
 		return testCase.Issue != nil
@@ -217,8 +497,7 @@ func (t *testSuite) SkippedTestCases() []TestCase {
 
 func (t *testSuite) FlakyTestCases() []TestCase {
 	return t.filterTestCases(func(testCase TestCase) bool {
-		return testCase.AmountFlakyErrors != 0 ||
-			testCase.AmountFlakyFailures != 0
+		return testCase.Status == Flaky
 	})
 }
 
@@ -254,6 +533,10 @@ func (r *testSuite) Skipped() int {
 	return r.skipped
 }
 
+func (r *testSuite) Quarantined() int {
+	return r.quarantined
+}
+
 func (r *testSuite) Name() string {
 	return r.name
 }
@@ -262,6 +545,10 @@ func (r *testSuite) Time() float64 {
 	return r.time
 }
 
+func (r *testSuite) Duration() time.Duration {
+	return toDuration(r.time)
+}
+
 func (r *testSuite) Labels() []string {
 	return r.labels
 }
@@ -270,6 +557,50 @@ func (r *testSuite) Filename() string {
 	return r.filename
 }
 
+func (r *testSuite) Module() string {
+	return r.module
+}
+
+func (r *testSuite) Properties() map[string]string {
+	return r.properties
+}
+
+func (r *testSuite) SystemOut() string {
+	return r.systemOut
+}
+
+func (r *testSuite) SystemErr() string {
+	return r.systemErr
+}
+
+func (r *testSuite) Incomplete() bool {
+	return r.incomplete
+}
+
+func (r *testSuite) Anomalies() []Anomaly {
+	return r.anomalies
+}
+
+func (r *testSuite) Timestamp() time.Time {
+	return r.timestamp
+}
+
+func (r *testSuite) Hostname() string {
+	return r.hostname
+}
+
+func (r *testSuite) ID() string {
+	return r.id
+}
+
+func (r *testSuite) Package() string {
+	return r.pkg
+}
+
+func (r *testSuite) Version() string {
+	return r.version
+}
+
 func (r *testResults) Successes() int {
 	return r.successes
 }
@@ -294,6 +625,60 @@ func (r *testResults) Flakes() int {
 	return r.flakes
 }
 
+func (r *testResults) Quarantined() int {
+	return r.quarantined
+}
+
+func (r *testResults) ExpiredQuarantine() []QuarantineEntry {
+	return r.expired
+}
+
+func (r *testResults) Counts(label string) LabelCounts {
+	return r.labelCounts[label]
+}
+
+func (r *testResults) FailsafeSummaries() []FailsafeSummary {
+	return r.summaries
+}
+
+func (r *testResults) SummaryMismatches() []SummaryMismatch {
+	return r.mismatches
+}
+
+func (r *testResults) SkippedFiles() []*ParseError {
+	return r.skippedFiles
+}
+
+func (r *testResults) RunWindow() RunWindow {
+	var window RunWindow
+	for _, suite := range r.suites {
+		start := suite.Timestamp()
+		if start.IsZero() {
+			continue
+		}
+		end := start.Add(suite.Duration())
+		if window.Start.IsZero() || start.Before(window.Start) {
+			window.Start = start
+		}
+		if end.After(window.End) {
+			window.End = end
+		}
+		window.CPUTime += suite.Duration()
+	}
+	window.WallClock = window.End.Sub(window.Start)
+
+	return window
+}
+
+// addSummaries adds failsafe summaries and checks them against the suites from the same directory
+func (r *testResults) addSummaries(summaries []surefireFailsafeSummary) {
+	for _, s := range summaries {
+		summary := toFailsafeSummary(s)
+		r.summaries = append(r.summaries, summary)
+		r.mismatches = append(r.mismatches, summaryMismatches(summary, r.suites)...)
+	}
+}
+
 func (r *testResults) append(suite *testSuite) {
 	r.tests += len(suite.TestCases())
 	r.successes += suite.Success()
@@ -301,5 +686,31 @@ func (r *testResults) append(suite *testSuite) {
 	r.failures += suite.Failure()
 	r.skipped += suite.Skipped()
 	r.flakes += len(suite.FlakyTestCases())
+	r.quarantined += suite.Quarantined()
 	r.suites = append(r.suites, suite)
+
+	for _, testCase := range suite.TestCases() {
+		for _, label := range testCase.Labels {
+			if r.labelCounts == nil {
+				r.labelCounts = make(map[string]LabelCounts)
+			}
+			r.labelCounts[label] = r.labelCounts[label].count(testCase.Status)
+		}
+	}
+}
+
+// count updates the counters of the suite by a test case of given status. Flaky test cases count as successful
+func (r *testSuite) count(status Status) {
+	switch status {
+	case Skip:
+		r.skipped++
+	case Failure:
+		r.failures++
+	case Error:
+		r.errors++
+	case Quarantined:
+		r.quarantined++
+	default:
+		r.successes++
+	}
 }
@@ -0,0 +1,114 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import "slices"
+
+// Merge combines the results of several runs, e.g. of a build sharded over many nodes, into one.
+// Suites with the same name and filename are taken only once. Suites of the same class read from different files
+// are merged into a single suite, which keeps the filename and attributes of the first one. All counters are recomputed
+func Merge(results ...TestResults) TestResults {
+	merged := &testResults{}
+	seen := make(map[[2]string]bool)
+	suites := make(map[string]*testSuite)
+	var order []*testSuite
+
+	for _, result := range results {
+		for _, suite := range result.TestSuites() {
+			key := [2]string{suite.Name(), suite.Filename()}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			mergedSuite, ok := suites[suite.Name()]
+			if !ok {
+				mergedSuite = newMergedSuite(suite)
+				suites[suite.Name()] = mergedSuite
+				order = append(order, mergedSuite)
+			}
+			mergedSuite.merge(suite)
+		}
+		merged.summaries = append(merged.summaries, result.FailsafeSummaries()...)
+		merged.mismatches = append(merged.mismatches, result.SummaryMismatches()...)
+		merged.skippedFiles = append(merged.skippedFiles, result.SkippedFiles()...)
+		for _, entry := range result.ExpiredQuarantine() {
+			if !slices.Contains(merged.expired, entry) {
+				merged.expired = append(merged.expired, entry)
+			}
+		}
+	}
+
+	for _, suite := range order {
+		merged.append(suite)
+	}
+
+	return merged
+}
+
+// newMergedSuite creates an empty suite with the attributes of suite
+func newMergedSuite(suite TestSuite) *testSuite {
+	return &testSuite{
+		name:       suite.Name(),
+		filename:   suite.Filename(),
+		module:     suite.Module(),
+		testcases:  make([]TestCase, 0),
+		labels:     make([]string, 0),
+		properties: make(map[string]string),
+		hostname:   suite.Hostname(),
+		id:         suite.ID(),
+		pkg:        suite.Package(),
+		version:    suite.Version(),
+	}
+}
+
+// merge adds the test cases and counters of suite. Properties already present are kept, output is appended
+func (r *testSuite) merge(suite TestSuite) {
+	for _, testCase := range suite.TestCases() {
+		testCase.Suite = r
+		r.testcases = append(r.testcases, testCase)
+	}
+	r.successes += suite.Success()
+	r.failures += suite.Failure()
+	r.errors += suite.Error()
+	r.skipped += suite.Skipped()
+	r.quarantined += suite.Quarantined()
+	r.time += suite.Time()
+
+	for _, label := range suite.Labels() {
+		if !slices.Contains(r.labels, label) {
+			r.labels = append(r.labels, label)
+		}
+	}
+	for name, value := range suite.Properties() {
+		if _, ok := r.properties[name]; !ok {
+			r.properties[name] = value
+		}
+	}
+	r.systemOut = joinOutput(r.systemOut, suite.SystemOut())
+	r.systemErr = joinOutput(r.systemErr, suite.SystemErr())
+	r.incomplete = r.incomplete || suite.Incomplete()
+	r.anomalies = append(r.anomalies, suite.Anomalies()...)
+	if timestamp := suite.Timestamp(); !timestamp.IsZero() && (r.timestamp.IsZero() || timestamp.Before(r.timestamp)) {
+		r.timestamp = timestamp
+	}
+}
+
+// joinOutput appends output on a new line
+func joinOutput(output string, more string) string {
+	if output == "" || more == "" {
+		return output + more
+	}
+
+	return output + "\n" + more
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"testing"
+	"time"
+
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestMergeShardedResults(t *testing.T) {
+	reader := NewJUnitReportsReaderBuilder().WithLabeler(func(suite TestSuite) []string {
+		return []string{suite.Hostname()}
+	}).Build()
+	flakySuite := surefireTestsuite{
+		Name:     "Flaky-Suite",
+		Filename: "node-1/TEST-Flaky-Suite.xml",
+		Hostname: "node-1",
+		Testcases: []surefireTestcase{
+			{Name: "Test-4", Classname: "Flaky-Suite", FlakyFailure: []surefireRerun{{Message: "Flaky-Failure-1"}}},
+		},
+	}
+	shard1 := reader.FromJUnitRepresentation([]surefireTestsuite{
+		{
+			Name:      "Split-Suite",
+			Filename:  "node-1/TEST-Split-Suite.xml",
+			Hostname:  "node-1",
+			Time:      1.5,
+			Timestamp: "2024-03-01T10:00:05",
+			SystemOut: "Output-1",
+			Testcases: []surefireTestcase{
+				{Name: "Test-1", Classname: "Split-Suite"},
+				{Name: "Test-2", Classname: "Split-Suite", Failure: &surefireProblem{Message: "Failure-1"}},
+			},
+		},
+		flakySuite,
+	})
+	shard2 := reader.FromJUnitRepresentation([]surefireTestsuite{
+		{
+			Name:      "Split-Suite",
+			Filename:  "node-2/TEST-Split-Suite.xml",
+			Hostname:  "node-2",
+			Time:      0.5,
+			Timestamp: "2024-03-01T10:00:00",
+			SystemOut: "Output-2",
+			Testcases: []surefireTestcase{
+				{Name: "Test-3", Classname: "Split-Suite", Error: &surefireProblem{Message: "Error-1"}},
+				{Name: "Test-5", Classname: "Split-Suite", Skipped: &surefireSkipped{Message: "Skipped-1"}},
+			},
+		},
+		// the same report uploaded twice
+		flakySuite,
+	})
+
+	assert := a.New(t)
+	merged := Merge(shard1, shard2)
+
+	assert.Equal(2, len(merged.TestSuites()))
+	assert.Equal(5, merged.Tests())
+	assert.Equal(2, merged.Successes())
+	assert.Equal(1, merged.Failures())
+	assert.Equal(1, merged.Errors())
+	assert.Equal(1, merged.Skipped())
+	assert.Equal(1, merged.Flakes())
+
+	split := suiteByName("Split-Suite", merged.TestSuites())
+	assert.NotNil(split)
+	assert.Equal("node-1/TEST-Split-Suite.xml", split.Filename())
+	assert.Equal(4, len(split.TestCases()))
+	assert.Equal(1, split.Success())
+	assert.Equal(1, split.Failure())
+	assert.Equal(1, split.Error())
+	assert.Equal(1, split.Skipped())
+	assert.Equal(2*time.Second, split.Duration())
+	assert.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), split.Timestamp())
+	assert.Equal([]string{"node-1", "node-2"}, split.Labels())
+	assert.Equal("Output-1\nOutput-2", split.SystemOut())
+	for _, testCase := range split.TestCases() {
+		assert.Same(split, testCase.Suite)
+	}
+
+	// the merged results are independent of those merged
+	assert.Equal(2, len(suiteByName("Split-Suite", shard1.TestSuites()).TestCases()))
+	assert.Equal(1, shard1.Failures())
+}
+
+func TestMergeNothing(t *testing.T) {
+	assert := a.New(t)
+	merged := Merge()
+
+	assert.Empty(merged.TestSuites())
+	assert.Equal(0, merged.Tests())
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import "slices"
+
+// ReconcileAttempts combines the results of retried runs of the same build, given from the first attempt to the last.
+// Test cases are joined by Fullname and take the outcome of the last attempt they ran in, where a test case which
+// failed or was in error before and succeeded at last becomes Flaky. Each test case records its outcome in
+// Attempts, for each attempt it ran in. Suites keep the attributes of the last attempt they ran in, as do failsafe
+// summaries and skipped files
+func ReconcileAttempts(attempts ...TestResults) TestResults {
+	reconciled := &testResults{}
+	if len(attempts) == 0 {
+		return reconciled
+	}
+
+	var suiteNames, fullnames []string
+	suites := make(map[string]TestSuite)
+	testCases := make(map[string]TestCase)
+	histories := make(map[string][]Attempt)
+
+	for i, attempt := range attempts {
+		for _, suite := range attempt.TestSuites() {
+			if _, ok := suites[suite.Name()]; !ok {
+				suiteNames = append(suiteNames, suite.Name())
+			}
+			suites[suite.Name()] = suite
+
+			for _, testCase := range suite.TestCases() {
+				if _, ok := testCases[testCase.Fullname]; !ok {
+					fullnames = append(fullnames, testCase.Fullname)
+				}
+				testCases[testCase.Fullname] = testCase
+				histories[testCase.Fullname] = append(histories[testCase.Fullname], Attempt{
+					Attempt: i,
+					Status:  testCase.Status,
+					Time:    testCase.Time,
+					Issue:   testCase.Issue,
+				})
+			}
+		}
+	}
+
+	reconciledSuites := make(map[string]*testSuite, len(suiteNames))
+	for _, name := range suiteNames {
+		reconciledSuites[name] = newReconciledSuite(suites[name])
+	}
+	for _, fullname := range fullnames {
+		testCase := testCases[fullname]
+		suite := reconciledSuites[testCase.Suite.Name()]
+
+		testCase.Suite = suite
+		testCase.Attempts = histories[fullname]
+		testCase.Status = reconciledStatus(testCase.Attempts)
+		suite.count(testCase.Status)
+		suite.testcases = append(suite.testcases, testCase)
+	}
+	for _, name := range suiteNames {
+		reconciled.append(reconciledSuites[name])
+	}
+
+	last := attempts[len(attempts)-1]
+	reconciled.summaries = last.FailsafeSummaries()
+	reconciled.mismatches = last.SummaryMismatches()
+	reconciled.skippedFiles = last.SkippedFiles()
+	reconciled.expired = last.ExpiredQuarantine()
+
+	return reconciled
+}
+
+// newReconciledSuite creates a suite with the attributes of suite but without test cases
+func newReconciledSuite(suite TestSuite) *testSuite {
+	reconciled := newMergedSuite(suite)
+	reconciled.time = suite.Time()
+	reconciled.timestamp = suite.Timestamp()
+	reconciled.labels = slices.Clone(suite.Labels())
+	reconciled.properties = suite.Properties()
+	reconciled.systemOut = suite.SystemOut()
+	reconciled.systemErr = suite.SystemErr()
+	reconciled.incomplete = suite.Incomplete()
+	reconciled.anomalies = suite.Anomalies()
+	return reconciled
+}
+
+// reconciledStatus is the status of the last attempt, or Flaky if a test case succeeded after it failed before,
+// whether it was quarantined or not
+func reconciledStatus(attempts []Attempt) Status {
+	status := attempts[len(attempts)-1].Status
+	if status == Success && slices.ContainsFunc(attempts, func(attempt Attempt) bool {
+		return attempt.Status == Failure || attempt.Status == Error || attempt.Status == Quarantined
+	}) {
+		return Flaky
+	}
+
+	return status
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"testing"
+
+	a "github.com/stretchr/testify/assert"
+)
+
+func TestReconcileRetriedRun(t *testing.T) {
+	reader := NewJUnitReportsReaderBuilder().Build()
+	first := reader.FromJUnitRepresentation([]surefireTestsuite{
+		{
+			Name: "Suite-A",
+			Testcases: []surefireTestcase{
+				{Name: "Test-1", Classname: "Suite-A", Time: 2, Failure: &surefireProblem{Message: "Failure-1"}},
+				{Name: "Test-2", Classname: "Suite-A"},
+			},
+		},
+		{
+			Name: "Suite-B",
+			Testcases: []surefireTestcase{
+				{Name: "Test-3", Classname: "Suite-B", Error: &surefireProblem{Message: "Error-1"}},
+				{Name: "Test-4", Classname: "Suite-B"},
+			},
+		},
+	})
+	// the retry only runs the suites which did not succeed
+	second := reader.FromJUnitRepresentation([]surefireTestsuite{
+		{
+			Name: "Suite-A",
+			Testcases: []surefireTestcase{
+				{Name: "Test-1", Classname: "Suite-A", Time: 1},
+				{Name: "Test-2", Classname: "Suite-A"},
+			},
+		},
+		{
+			Name: "Suite-B",
+			Testcases: []surefireTestcase{
+				{Name: "Test-3", Classname: "Suite-B", Error: &surefireProblem{Message: "Error-2"}},
+			},
+		},
+	})
+
+	assert := a.New(t)
+	reconciled := ReconcileAttempts(first, second)
+
+	assert.Equal(4, reconciled.Tests())
+	assert.Equal(3, reconciled.Successes())
+	assert.Equal(0, reconciled.Failures())
+	assert.Equal(1, reconciled.Errors())
+	assert.Equal(1, reconciled.Flakes())
+
+	suiteA := suiteByName("Suite-A", reconciled.TestSuites())
+	assert.NotNil(suiteA)
+	test1 := caseByName("Test-1", suiteA.TestCases())
+	assert.Equal(Flaky, test1.Status)
+	assert.Nil(test1.Issue)
+	assert.Equal(1.0, test1.Time)
+	assert.Same(suiteA, test1.Suite)
+	assert.Equal([]Attempt{
+		{Attempt: 0, Status: Failure, Time: 2, Issue: &Issue{Message: "Failure-1"}},
+		{Attempt: 1, Status: Success, Time: 1},
+	}, test1.Attempts)
+
+	suiteB := suiteByName("Suite-B", reconciled.TestSuites())
+	assert.NotNil(suiteB)
+	test3 := caseByName("Test-3", suiteB.TestCases())
+	assert.Equal(Error, test3.Status)
+	assert.Equal("Error-2", test3.Issue.Message)
+	assert.Equal(2, len(test3.Attempts))
+	assert.Equal(1, test3.Attempts[1].Attempt)
+	// not run again by the retry
+	test4 := caseByName("Test-4", suiteB.TestCases())
+	assert.Equal(Success, test4.Status)
+	assert.Equal(1, len(test4.Attempts))
+	assert.Equal(0, test4.Attempts[0].Attempt)
+	assert.Equal(1, suiteB.Success())
+	assert.Equal(1, suiteB.Error())
+}
+
+func TestReconcileQuarantinedAttempt(t *testing.T) {
+	assert := a.New(t)
+	first := NewJUnitReportsReaderBuilder().WithQuarantine(QuarantineEntry{Pattern: "Suite-A.*"}).Build().
+		FromJUnitRepresentation([]surefireTestsuite{{Name: "Suite-A", Testcases: []surefireTestcase{
+			{Name: "Test-1", Classname: "Suite-A", Failure: &surefireProblem{Message: "Failure-1"}},
+		}}})
+	second := NewJUnitReportsReaderBuilder().Build().FromJUnitRepresentation([]surefireTestsuite{{Name: "Suite-A", Testcases: []surefireTestcase{
+		{Name: "Test-1", Classname: "Suite-A"},
+	}}})
+
+	reconciled := ReconcileAttempts(first, second)
+	assert.Equal(1, reconciled.Flakes())
+	assert.Equal(Quarantined, reconciled.TestSuites()[0].TestCases()[0].Attempts[0].Status)
+	assert.Equal(Quarantined, ReconcileAttempts(second, first).TestSuites()[0].TestCases()[0].Status)
+}
+
+func TestReconcileSingleAttempt(t *testing.T) {
+	assert := a.New(t)
+	attempt := NewJUnitReportsReaderBuilder().Build().FromJUnitRepresentation([]surefireTestsuite{
+		{
+			Name: "Suite-A",
+			Testcases: []surefireTestcase{
+				{Name: "Test-1", Classname: "Suite-A", Failure: &surefireProblem{Message: "Failure-1"}},
+			},
+		},
+	})
+	reconciled := ReconcileAttempts(attempt)
+
+	assert.Equal(1, reconciled.Failures())
+	assert.Equal(0, reconciled.Flakes())
+	assert.Equal(0, ReconcileAttempts().Tests())
+}
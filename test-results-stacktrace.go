@@ -0,0 +1,219 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrNoStackTrace is returned when parsing text which does not start with a Java exception
+var ErrNoStackTrace = errors.New("not a stack trace")
+
+// StackTrace is a Java exception along with its frames, cause and suppressed exceptions
+type StackTrace struct {
+	// Full qualified class name of the exception
+	Exception string
+
+	// Message of the exception, empty if it has none
+	Message string
+
+	// Frames from the innermost call, including those in common with the enclosing trace
+	Frames []StackFrame
+
+	// The amount of frames at the end of Frames in common with the enclosing trace, printed as "... N more"
+	CommonFrames int
+
+	// The exception which caused this one, nil if there is none
+	Cause *StackTrace
+
+	// Exceptions suppressed in favor of this one
+	Suppressed []*StackTrace
+}
+
+// StackFrame is a single frame of a StackTrace
+type StackFrame struct {
+	// Class loader and module the class was loaded by, e.g. java.base@17.0.2, empty if not printed
+	Module string
+
+	// Full qualified class name
+	Class string
+
+	Method string
+
+	// Source file, empty if unknown
+	File string
+
+	// Line in the source file, 0 if unknown
+	Line int
+
+	// Whether the method is native
+	Native bool
+}
+
+var (
+	// full qualified class name of an exception
+	exceptionClass = regexp.MustCompile(`^[\p{L}_$][\p{L}\p{N}_$]*(\.[\p{L}_$][\p{L}\p{N}_$]*)+$`)
+	// class loader and module of a frame, e.g. "app//" or "java.base@17.0.2/"
+	frameModule = regexp.MustCompile(`^(?:[\w.@-]*/)+`)
+)
+
+// StackTrace parses the detail of the issue
+func (i *Issue) StackTrace() (*StackTrace, error) {
+	return ParseStackTrace(i.Detail)
+}
+
+// ParseStackTrace parses a Java stack trace as printed by Throwable.printStackTrace. Messages spanning several lines
+// are kept, frames omitted as "... N more" are taken from the enclosing trace
+func ParseStackTrace(text string) (*StackTrace, error) {
+	var root, current *StackTrace
+	// the most recent trace per level of indentation, and the trace enclosing each trace
+	var levels []*StackTrace
+	enclosing := make(map[*StackTrace]*StackTrace)
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		level, content := indentation(line)
+		if content == "" {
+			continue
+		}
+
+		if root == nil {
+			root = parseExceptionHeader(content)
+			if root == nil {
+				return nil, ErrNoStackTrace
+			}
+			current, levels = root, []*StackTrace{root}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(content, "at "):
+			current.Frames = append(current.Frames, parseStackFrame(strings.TrimPrefix(content, "at ")))
+		case strings.HasPrefix(content, "... ") && strings.HasSuffix(content, " more"):
+			common, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(content, "... "), " more"))
+			if parent := enclosing[current]; err == nil && parent != nil {
+				current.CommonFrames = min(common, len(parent.Frames))
+				current.Frames = append(current.Frames, parent.Frames[len(parent.Frames)-current.CommonFrames:]...)
+			}
+		case strings.HasPrefix(content, "Caused by: "):
+			trace := parseExceptionHeader(strings.TrimPrefix(content, "Caused by: "))
+			level = min(level, len(levels)-1)
+			if trace == nil {
+				continue
+			}
+			levels[level].Cause = trace
+			enclosing[trace] = levels[level]
+			levels = append(levels[:level], trace)
+			current = trace
+		case strings.HasPrefix(content, "Suppressed: "):
+			trace := parseExceptionHeader(strings.TrimPrefix(content, "Suppressed: "))
+			level = max(1, min(level, len(levels)))
+			if trace == nil {
+				continue
+			}
+			levels[level-1].Suppressed = append(levels[level-1].Suppressed, trace)
+			enclosing[trace] = levels[level-1]
+			levels = append(levels[:level], trace)
+			current = trace
+		case len(current.Frames) == 0:
+			// continuation of a message spanning several lines
+			current.Message += "\n" + strings.TrimRight(line, " \t")
+		}
+	}
+
+	if root == nil {
+		return nil, ErrNoStackTrace
+	}
+
+	return root, nil
+}
+
+// indentation returns the level of indentation of a line, where a tab or up to four spaces make up a level,
+// and the line without indentation and trailing whitespace
+func indentation(line string) (int, string) {
+	content := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(content)]
+	spaces := strings.Count(indent, " ")
+
+	return strings.Count(indent, "\t") + (spaces+3)/4, strings.TrimRight(content, " \t")
+}
+
+// parseExceptionHeader parses "java.lang.IllegalStateException: message", nil if the line does not start with a class name
+func parseExceptionHeader(line string) *StackTrace {
+	exception, message, _ := strings.Cut(line, ": ")
+	exception = strings.TrimSuffix(exception, ":")
+	if !exceptionClass.MatchString(exception) {
+		return nil
+	}
+
+	return &StackTrace{Exception: exception, Message: message}
+}
+
+// parseStackFrame parses a frame as in "java.base@17.0.2/java.util.Optional.orElseGet(Optional.java:369)"
+func parseStackFrame(frame string) StackFrame {
+	method, location, _ := strings.Cut(frame, "(")
+	location, _, _ = strings.Cut(location, ")")
+
+	var result StackFrame
+	if module := frameModule.FindString(method); module != "" {
+		result.Module = strings.TrimRight(module, "/")
+		method = method[len(module):]
+	}
+	if dot := strings.LastIndex(method, "."); dot >= 0 {
+		result.Class, result.Method = method[:dot], method[dot+1:]
+	} else {
+		result.Method = method
+	}
+
+	switch location {
+	case "Native Method":
+		result.Native = true
+	case "Unknown Source", "":
+	default:
+		file, line, found := strings.Cut(location, ":")
+		result.File = file
+		if found {
+			result.Line, _ = strconv.Atoi(line)
+		}
+	}
+
+	return result
+}
+
+// RootCause returns the innermost cause of the trace, the trace itself if it has no cause
+func (s *StackTrace) RootCause() *StackTrace {
+	root := s
+	for root.Cause != nil {
+		root = root.Cause
+	}
+
+	return root
+}
+
+// ProjectFrame returns the first frame of a class in one of the given packages, e.g. of the project under test.
+// Frames of the trace are searched before those of its causes. Returns nil if no frame belongs to the packages
+func (s *StackTrace) ProjectFrame(packagePrefixes ...string) *StackFrame {
+	for trace := s; trace != nil; trace = trace.Cause {
+		for i, frame := range trace.Frames {
+			for _, prefix := range packagePrefixes {
+				if strings.HasPrefix(frame.Class, prefix) {
+					return &trace.Frames[i]
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2023 Adobe. All rights reserved.
+This file is licensed to you under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License. You may obtain a copy
+of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed under
+the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR REPRESENTATIONS
+OF ANY KIND, either express or implied. See the License for the specific language
+governing permissions and limitations under the License.
+*/
+
+package surefire
+
+import (
+	"testing"
+
+	a "github.com/stretchr/testify/assert"
+)
+
+const nestedStacktrace = `java.lang.IllegalStateException: could not save
+expected a connection
+	at org.example.db.Repository.save(Repository.java:42)
+	at java.base/java.util.Optional.orElseGet(Optional.java:369)
+	at app//org.example.RepositoryTest.saves(RepositoryTest.java:21)
+	at java.base/jdk.internal.reflect.NativeMethodAccessorImpl.invoke0(Native Method)
+	Suppressed: java.io.IOException: close failed
+		at org.example.db.Pool.close(Unknown Source)
+		at org.example.db.Repository.save(Repository.java:45)
+		... 3 more
+		Caused by: java.net.SocketException: reset
+			at org.example.db.Socket.close(Socket.java:7)
+			... 4 more
+Caused by: org.example.db.PoolException: exhausted
+	at org.example.db.Pool$$Lambda$123/0x0000000800c4b040.get(Unknown Source)
+	at org.example.db.Pool.acquire(Pool.java:17)
+	... 3 more
+Caused by: java.net.ConnectException
+	at java.base/sun.nio.ch.Net.connect0(Native Method)
+	... 4 more
+`
+
+func TestParseStackTrace(t *testing.T) {
+	assert := a.New(t)
+	trace, err := ParseStackTrace(nestedStacktrace)
+	assert.Nil(err)
+
+	assert.Equal("java.lang.IllegalStateException", trace.Exception)
+	assert.Equal("could not save\nexpected a connection", trace.Message)
+	assert.Equal(4, len(trace.Frames))
+	assert.Equal(0, trace.CommonFrames)
+	assert.Equal(StackFrame{Class: "org.example.db.Repository", Method: "save", File: "Repository.java", Line: 42}, trace.Frames[0])
+	assert.Equal(StackFrame{Module: "java.base", Class: "java.util.Optional", Method: "orElseGet", File: "Optional.java", Line: 369}, trace.Frames[1])
+	assert.Equal("app", trace.Frames[2].Module)
+	assert.True(trace.Frames[3].Native)
+
+	assert.Equal(1, len(trace.Suppressed))
+	suppressed := trace.Suppressed[0]
+	assert.Equal("java.io.IOException", suppressed.Exception)
+	assert.Equal(StackFrame{Class: "org.example.db.Pool", Method: "close"}, suppressed.Frames[0])
+	assert.Equal(5, len(suppressed.Frames))
+	assert.Equal(3, suppressed.CommonFrames)
+	assert.Equal(trace.Frames[1:], suppressed.Frames[2:])
+	assert.Equal("java.net.SocketException", suppressed.Cause.Exception)
+	assert.Equal(5, len(suppressed.Cause.Frames))
+
+	cause := trace.Cause
+	assert.Equal("org.example.db.PoolException", cause.Exception)
+	assert.Equal("exhausted", cause.Message)
+	assert.Equal("org.example.db.Pool$$Lambda$123/0x0000000800c4b040", cause.Frames[0].Class)
+	assert.Equal(5, len(cause.Frames))
+	assert.Empty(cause.Suppressed)
+
+	root := trace.RootCause()
+	assert.Equal("java.net.ConnectException", root.Exception)
+	assert.Empty(root.Message)
+	assert.Equal(5, len(root.Frames))
+	assert.Equal(cause.Frames[1:], root.Frames[1:])
+	assert.Same(root, root.RootCause())
+}
+
+func TestProjectFrame(t *testing.T) {
+	assert := a.New(t)
+	issue := &Issue{Detail: nestedStacktrace}
+	trace, err := issue.StackTrace()
+	assert.Nil(err)
+
+	frame := trace.ProjectFrame("org.example.")
+	assert.Equal("Repository.java", frame.File)
+	assert.Equal(42, frame.Line)
+
+	frame = trace.ProjectFrame("org.example.RepositoryTest", "com.example.")
+	assert.Equal("saves", frame.Method)
+
+	// causes are searched as well
+	assert.Equal("sun.nio.ch.Net", trace.ProjectFrame("sun.nio.").Class)
+	assert.Nil(trace.ProjectFrame("com.example."))
+}
+
+func TestParseNoStackTrace(t *testing.T) {
+	assert := a.New(t)
+	for _, text := range []string{"", "\n\n", "expected: <1> but was: <2>", "Something went wrong"} {
+		_, err := ParseStackTrace(text)
+		assert.ErrorIs(err, ErrNoStackTrace, text)
+	}
+
+	trace, err := ParseStackTrace("org.opentest4j.AssertionFailedError: expected: <1> but was: <2>")
+	assert.Nil(err)
+	assert.Equal("expected: <1> but was: <2>", trace.Message)
+	assert.Empty(trace.Frames)
+}